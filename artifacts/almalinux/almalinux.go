@@ -113,15 +113,17 @@ func (a *almalinux) Tests() []api.ArtifactTest {
 	return []api.ArtifactTest{
 		tests.GuestOsInfo,
 		tests.SSH,
+		tests.CloudInit,
+		tests.OSRelease(tests.OSIdentity{ID: "almalinux", VersionID: a.Version, Arch: a.Arch}),
 	}
 }
 
-func New(release, arch string, exampleUserData *docs.UserData, envVariables map[string]string) *almalinux {
+func New(getter http.Getter, release, arch string, exampleUserData *docs.UserData, envVariables map[string]string) *almalinux {
 	return &almalinux{
 		Version:         release,
 		Arch:            arch,
 		Variant:         "GenericCloud",
-		getter:          &http.HTTPGetter{},
+		getter:          getter,
 		ExampleUserData: exampleUserData,
 		EnvVariables:    envVariables,
 	}
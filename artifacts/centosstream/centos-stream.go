@@ -120,16 +120,18 @@ func (c *centos) Tests() []api.ArtifactTest {
 	return []api.ArtifactTest{
 		tests.GuestOsInfo,
 		tests.SSH,
+		tests.CloudInit,
+		tests.OSRelease(tests.OSIdentity{ID: "centos", VersionID: c.Version, Arch: c.Arch}),
 	}
 }
 
 // New accepts CentOS Stream 8 and 9 versions.
-func New(release, arch string, exampleUserData *docs.UserData, envVariables map[string]string) *centos {
+func New(getter http.Getter, release, arch string, exampleUserData *docs.UserData, envVariables map[string]string) *centos {
 	return &centos{
 		Version:         release,
 		Arch:            arch,
 		Variant:         "GenericCloud",
-		getter:          &http.HTTPGetter{},
+		getter:          getter,
 		ExampleUserData: exampleUserData,
 		EnvVariables:    envVariables,
 	}
@@ -158,15 +158,17 @@ func (d *debian) UserData(data *docs.UserData) string {
 func (d *debian) Tests() []api.ArtifactTest {
 	return []api.ArtifactTest{
 		tests.SSH,
+		tests.CloudInit,
+		tests.OSRelease(tests.OSIdentity{ID: "debian", VersionID: d.Version, Arch: d.Arch}),
 	}
 }
 
-func New(version, versionName, arch string, exampleUserData *docs.UserData, envVariables map[string]string) *debian {
+func New(getter http.Getter, version, versionName, arch string, exampleUserData *docs.UserData, envVariables map[string]string) *debian {
 	return &debian{
 		Version:         version,
 		Arch:            arch,
 		VersionName:     versionName,
-		getter:          &http.HTTPGetter{},
+		getter:          getter,
 		ExampleUserData: exampleUserData,
 		envVariables:    envVariables,
 	}
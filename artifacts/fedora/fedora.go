@@ -56,6 +56,9 @@ const (
 	amd64Arch      = "x86_64"
 	arm64Arch      = "aarch64"
 	s390xArch      = "s390x"
+
+	downloadHost      = "https://download.fedoraproject.org/"
+	primaryMirrorHost = "https://dl.fedoraproject.org/"
 )
 
 //nolint:lll
@@ -97,6 +100,10 @@ func (f *fedora) Inspect() (*api.ArtifactDetails, error) {
 			DownloadURL:       release.Link,
 			ImageArchitecture: architecture.GetImageArchitecture(f.Arch),
 		}
+		// download.fedoraproject.org redirects to a mirror which may be broken, fall back to the primary mirror
+		if mirrorURL, ok := strings.CutPrefix(release.Link, downloadHost); ok {
+			details.MirrorURLs = []string{primaryMirrorHost + mirrorURL}
+		}
 
 		components := strings.Split(release.Link, "/")
 		fileName := components[len(components)-1]
@@ -136,6 +143,9 @@ func (f *fedora) Tests() []api.ArtifactTest {
 	return []api.ArtifactTest{
 		tests.GuestOsInfo,
 		tests.SSH,
+		tests.CloudInit,
+		// Prereleases like "44-beta" report the plain version
+		tests.OSRelease(tests.OSIdentity{ID: "fedora", VersionID: strings.Split(f.Version, "-")[0], Arch: f.Arch}),
 	}
 }
 
@@ -178,7 +188,7 @@ func (f *fedoraGatherer) Gather() ([][]api.Artifact, error) {
 		releases := versions[key]
 		var releaseArtifcats []api.Artifact
 		for _, release := range releases {
-			artifact := New(release.Version, release.Arch)
+			artifact := New(f.getter, release.Version, release.Arch)
 			releaseArtifcats = append(releaseArtifcats, artifact)
 		}
 		artifacts = append(artifacts, releaseArtifcats)
@@ -210,7 +220,11 @@ func (f *fedora) releaseMatches(release *Release) bool {
 // versionNumber extracts the leading numeric portion of a version string.
 // For example "44 Beta" returns 44, and "43" returns 43.
 func versionNumber(version string) (int, error) {
-	return strconv.Atoi(strings.Fields(version)[0])
+	fields := strings.Fields(version)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("empty version")
+	}
+	return strconv.Atoi(fields[0])
 }
 
 // IsStableVersion returns true if the version string is a pure integer
@@ -275,23 +289,23 @@ func (f *fedora) setEnvVariables() {
 	}
 }
 
-func New(release, arch string) *fedora {
+func New(getter http.Getter, release, arch string) *fedora {
 	f := &fedora{
 		Version:        NormalizeVersion(release),
 		ReleaseVersion: release,
 		Arch:           arch,
 		Variant:        "Cloud",
-		getter:         &http.HTTPGetter{},
+		getter:         getter,
 	}
 	f.setEnvVariables()
 	return f
 }
 
-func NewGatherer() *fedoraGatherer {
+func NewGatherer(getter http.Getter) *fedoraGatherer {
 	return &fedoraGatherer{
 		Archs:      []string{amd64Arch, arm64Arch, s390xArch},
 		Variant:    "Cloud",
 		Subvariant: "Cloud_Base",
-		getter:     &http.HTTPGetter{},
+		getter:     getter,
 	}
 }
@@ -1,6 +1,8 @@
 package fedora
 
 import (
+	"reflect"
+	"runtime"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -15,9 +17,8 @@ import (
 
 var _ = Describe("Fedora", func() {
 	DescribeTable("Inspect should be able to parse releases files",
-		func(release, arch, mockFile string, details *api.ArtifactDetails, metadata *api.Metadata) {
-			c := New(release, arch)
-			c.getter = testutil.NewMockGetter(mockFile)
+		func(release, arch string, details *api.ArtifactDetails, metadata *api.Metadata) {
+			c := New(testutil.NewFixtureGetter("testdata"), release, arch)
 			got, err := c.Inspect()
 			Expect(err).NotTo(HaveOccurred())
 			Expect(got.ChecksumHash).ToNot(BeNil())
@@ -28,7 +29,7 @@ var _ = Describe("Fedora", func() {
 			Expect(got.Compression).To(Equal(details.Compression))
 			Expect(c.Metadata()).To(Equal(metadata))
 		},
-		Entry("fedora:40 x86_64", "40", "x86_64", "testdata/releases.json",
+		Entry("fedora:40 x86_64", "40", "x86_64",
 			&api.ArtifactDetails{
 				Checksum:             "ac58f3c35b73272d5986fa6d3bc44fd246b45df4c334e99a07b3bbd00684adee",
 				DownloadURL:          "https://download.fedoraproject.org/pub/fedora/linux/releases/40/Cloud/x86_64/images/Fedora-Cloud-Base-Generic.x86_64-40-1.14.qcow2", //nolint:lll
@@ -50,7 +51,7 @@ var _ = Describe("Fedora", func() {
 				IsStable: true,
 			},
 		),
-		Entry("fedora:40 aarch64", "40", "aarch64", "testdata/releases.json",
+		Entry("fedora:40 aarch64", "40", "aarch64",
 			&api.ArtifactDetails{
 				Checksum:             "ebdce26d861a9d15072affe1919ed753ec7015bd97b3a7d0d0df6a10834f7459",
 				DownloadURL:          "https://download.fedoraproject.org/pub/fedora/linux/releases/40/Cloud/aarch64/images/Fedora-Cloud-Base-Generic.aarch64-40-1.14.qcow2", //nolint:lll
@@ -72,7 +73,7 @@ var _ = Describe("Fedora", func() {
 				IsStable: true,
 			},
 		),
-		Entry("fedora:40 s390x", "40", "s390x", "testdata/releases.json",
+		Entry("fedora:40 s390x", "40", "s390x",
 			&api.ArtifactDetails{
 				Checksum:             "808226b31c6c61e08cde77fe7ba61d766f7528c857e7ae8553040c177cbda9a7",
 				DownloadURL:          "https://download.fedoraproject.org/pub/fedora-secondary/releases/40/Cloud/s390x/images/Fedora-Cloud-Base-Generic.s390x-40-1.14.qcow2", //nolint:lll
@@ -94,7 +95,7 @@ var _ = Describe("Fedora", func() {
 				IsStable: true,
 			},
 		),
-		Entry("fedora:39 x86_64", "39", "x86_64", "testdata/releases.json",
+		Entry("fedora:39 x86_64", "39", "x86_64",
 			&api.ArtifactDetails{
 				Checksum:             "ab5be5058c5c839528a7d6373934e0ce5ad6c8f80bd71ed3390032027da52f37",
 				DownloadURL:          "https://download.fedoraproject.org/pub/fedora/linux/releases/39/Cloud/x86_64/images/Fedora-Cloud-Base-39-1.5.x86_64.qcow2", //nolint:lll
@@ -116,7 +117,7 @@ var _ = Describe("Fedora", func() {
 				IsStable: true,
 			},
 		),
-		Entry("fedora:39 aarch64", "39", "aarch64", "testdata/releases.json",
+		Entry("fedora:39 aarch64", "39", "aarch64",
 			&api.ArtifactDetails{
 				Checksum:             "765996d5b77481ca02d0ac06405641bf134ac920cfc1e60d981c64d7971162dc",
 				DownloadURL:          "https://download.fedoraproject.org/pub/fedora/linux/releases/39/Cloud/aarch64/images/Fedora-Cloud-Base-39-1.5.aarch64.qcow2", //nolint:lll
@@ -138,7 +139,7 @@ var _ = Describe("Fedora", func() {
 				IsStable: true,
 			},
 		),
-		Entry("fedora:39 s390x", "39", "s390x", "testdata/releases.json",
+		Entry("fedora:39 s390x", "39", "s390x",
 			&api.ArtifactDetails{
 				Checksum:             "36dec66c791c9d1225d74e8828fdb0976ad89f695e8e6f5c93269cafa8563907",
 				DownloadURL:          "https://download.fedoraproject.org/pub/fedora-secondary/releases/39/Cloud/s390x/images/Fedora-Cloud-Base-39-1.5.s390x.qcow2", //nolint:lll
@@ -160,7 +161,7 @@ var _ = Describe("Fedora", func() {
 				IsStable: true,
 			},
 		),
-		Entry("fedora:41-beta aarch64", "41 Beta", "aarch64", "testdata/releases.json",
+		Entry("fedora:41-beta aarch64", "41 Beta", "aarch64",
 			&api.ArtifactDetails{
 				Checksum:             "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
 				DownloadURL:          "https://download.fedoraproject.org/pub/fedora/linux/releases/test/41_Beta/Cloud/aarch64/images/Fedora-Cloud-Base-Generic-41_Beta-1.2.aarch64.qcow2", //nolint:lll
@@ -186,24 +187,24 @@ var _ = Describe("Fedora", func() {
 	It("Gather should be able to parse releases files", func() {
 		// Stable versions should come first (sorted descending), followed by
 		// prerelease versions. This ensures stable releases get the "latest" tag.
+		getter := testutil.NewFixtureGetter("testdata")
 		artifacts := [][]api.Artifact{
 			{
-				parsedRelease("40", "40", "x86_64", defaultPreferenceX86_64),
-				parsedRelease("40", "40", "aarch64", defaultPreferenceAarch64),
+				parsedRelease(getter, "40", "40", "x86_64", defaultPreferenceX86_64),
+				parsedRelease(getter, "40", "40", "aarch64", defaultPreferenceAarch64),
 			},
 			{
-				parsedRelease("39", "39", "x86_64", defaultPreferenceX86_64),
-				parsedRelease("39", "39", "aarch64", defaultPreferenceAarch64),
-				parsedRelease("39", "39", "s390x", defaultPreferenceS390x),
+				parsedRelease(getter, "39", "39", "x86_64", defaultPreferenceX86_64),
+				parsedRelease(getter, "39", "39", "aarch64", defaultPreferenceAarch64),
+				parsedRelease(getter, "39", "39", "s390x", defaultPreferenceS390x),
 			},
 			{
-				parsedRelease("41-beta", "41 Beta", "x86_64", defaultPreferenceX86_64),
-				parsedRelease("41-beta", "41 Beta", "aarch64", defaultPreferenceAarch64),
+				parsedRelease(getter, "41-beta", "41 Beta", "x86_64", defaultPreferenceX86_64),
+				parsedRelease(getter, "41-beta", "41 Beta", "aarch64", defaultPreferenceAarch64),
 			},
 		}
 
-		c := NewGatherer()
-		c.getter = testutil.NewMockGetter("testdata/releases.json")
+		c := NewGatherer(getter)
 		got, err := c.Gather()
 		Expect(err).NotTo(HaveOccurred())
 		Expect(got).To(Equal(artifacts))
@@ -218,6 +219,26 @@ var _ = Describe("Fedora", func() {
 		Entry("rc", "44 RC1", false),
 	)
 
+	It("Inspect should fall back to the primary mirror", func() {
+		c := New(testutil.NewFixtureGetter("testdata"), "40", "x86_64")
+		got, err := c.Inspect()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.MirrorURLs).To(Equal([]string{
+			"https://dl.fedoraproject.org/pub/fedora/linux/releases/40/Cloud/x86_64/images/Fedora-Cloud-Base-Generic.x86_64-40-1.14.qcow2",
+		}))
+	})
+
+	It("Tests should not depend on the release version", func() {
+		prerelease := New(nil, "44-beta", "x86_64").Tests()
+		Expect(prerelease).ToNot(BeEmpty())
+		Expect(testNames(prerelease)).To(Equal(testNames(New(nil, "43", "x86_64").Tests())))
+	})
+
+	It("Gather should skip releases without a version", func() {
+		c := NewGatherer(nil)
+		Expect(c.releaseMatches(&Release{Arch: "x86_64", Variant: "Cloud", Subvariant: "Cloud_Base"})).To(BeFalse())
+	})
+
 	DescribeTable("NormalizeVersion",
 		func(version, expected string) {
 			Expect(NormalizeVersion(version)).To(Equal(expected))
@@ -227,13 +248,23 @@ var _ = Describe("Fedora", func() {
 	)
 })
 
-func parsedRelease(version, releaseVersion, arch, defaultPreference string) api.Artifact {
+// testNames returns the function names of tests, tests created by the same constructor have the same name.
+func testNames(tests []api.ArtifactTest) []string {
+	names := make([]string, 0, len(tests))
+	for _, test := range tests {
+		names = append(names, runtime.FuncForPC(reflect.ValueOf(test).Pointer()).Name())
+	}
+
+	return names
+}
+
+func parsedRelease(getter http.Getter, version, releaseVersion, arch, defaultPreference string) api.Artifact {
 	return &fedora{
 		Version:        version,
 		ReleaseVersion: releaseVersion,
 		Arch:           arch,
 		Variant:        "Cloud",
-		getter:         &http.HTTPGetter{},
+		getter:         getter,
 		EnvVariables: map[string]string{
 			common.DefaultInstancetypeEnv: defaultInstancetype,
 			common.DefaultPreferenceEnv:   defaultPreference,
@@ -5,6 +5,7 @@ import (
 
 	"kubevirt.io/containerdisks/pkg/api"
 	"kubevirt.io/containerdisks/pkg/docs"
+	"kubevirt.io/containerdisks/pkg/tests"
 )
 
 type generic struct {
@@ -32,7 +33,9 @@ func (c *generic) UserData(_ *docs.UserData) string {
 }
 
 func (c *generic) Tests() []api.ArtifactTest {
-	return []api.ArtifactTest{}
+	return []api.ArtifactTest{
+		tests.Console,
+	}
 }
 
 func New(artifactDetails *api.ArtifactDetails, metadata *api.Metadata) *generic {
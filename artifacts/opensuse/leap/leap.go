@@ -97,15 +97,17 @@ func (l *leap) UserData(data *docs.UserData) string {
 func (l *leap) Tests() []api.ArtifactTest {
 	return []api.ArtifactTest{
 		tests.SSH,
+		tests.CloudInit,
+		tests.OSRelease(tests.OSIdentity{ID: "opensuse-leap", VersionID: l.Version, Arch: l.Arch}),
 	}
 }
 
-func New(arch, version, username string, envVariables map[string]string) *leap {
+func New(getter http.Getter, arch, version, username string, envVariables map[string]string) *leap {
 	return &leap{
 		Arch:         arch,
 		Version:      version,
 		Username:     username,
-		getter:       &http.HTTPGetter{},
+		getter:       getter,
 		envVariables: envVariables,
 	}
 }
@@ -14,9 +14,8 @@ import (
 
 var _ = Describe("openSUSE Leap", func() {
 	DescribeTable("Inspect should be able to parse checksum files",
-		func(arch, version, username, mockFile string, envVariables map[string]string, details *api.ArtifactDetails, metadata *api.Metadata) {
-			c := New(arch, version, username, envVariables)
-			c.getter = testutil.NewMockGetter(mockFile)
+		func(arch, version, username string, envVariables map[string]string, details *api.ArtifactDetails, metadata *api.Metadata) {
+			c := New(testutil.NewFixtureGetter("testdata"), arch, version, username, envVariables)
 			got, err := c.Inspect()
 			Expect(err).NotTo(HaveOccurred())
 			Expect(got.ChecksumHash).ToNot(BeNil())
@@ -28,7 +27,7 @@ var _ = Describe("openSUSE Leap", func() {
 			Expect(c.Metadata()).To(Equal(metadata))
 			Expect(err).NotTo(HaveOccurred())
 		},
-		Entry("leap:15.6 x86_64", "x86_64", "15.6", "opensuse", "testdata/openSUSE-Leap-15.6-Minimal-VM.x86_64-Cloud.qcow2.sha256",
+		Entry("leap:15.6 x86_64", "x86_64", "15.6", "opensuse",
 			map[string]string{
 				common.DefaultInstancetypeEnv: "u1.medium",
 				common.DefaultPreferenceEnv:   "opensuse.leap",
@@ -52,7 +51,7 @@ var _ = Describe("openSUSE Leap", func() {
 				Arch: "x86_64",
 			},
 		),
-		Entry("leap:15.6 aarch64", "aarch64", "15.6", "opensuse", "testdata/openSUSE-Leap-15.6-Minimal-VM.aarch64-Cloud.qcow2.sha256",
+		Entry("leap:15.6 aarch64", "aarch64", "15.6", "opensuse",
 			nil,
 			&api.ArtifactDetails{
 				Checksum:          "d2ff40176f8823ab869bf4d728f827ffd6c7f180940b9ccca865be6dc20b06dd",
@@ -69,7 +68,7 @@ var _ = Describe("openSUSE Leap", func() {
 				Arch: "aarch64",
 			},
 		),
-		Entry("leap:15.5 x86_64", "x86_64", "15.5", "opensuse", "testdata/openSUSE-Leap-15.5-Minimal-VM.x86_64-Cloud.qcow2.sha256",
+		Entry("leap:15.5 x86_64", "x86_64", "15.5", "opensuse",
 			map[string]string{
 				common.DefaultInstancetypeEnv: "u1.medium",
 				common.DefaultPreferenceEnv:   "opensuse.leap",
@@ -93,7 +92,7 @@ var _ = Describe("openSUSE Leap", func() {
 				Arch: "x86_64",
 			},
 		),
-		Entry("leap:15.5 aarch64", "aarch64", "15.5", "opensuse", "testdata/openSUSE-Leap-15.5-Minimal-VM.aarch64-Cloud.qcow2.sha256",
+		Entry("leap:15.5 aarch64", "aarch64", "15.5", "opensuse",
 			nil,
 			&api.ArtifactDetails{
 				Checksum:          "3560ca0845d797880a1a36ca84b52a6ba1d0bb1e153913312c5e9f3c9cfda56a",
@@ -110,7 +109,7 @@ var _ = Describe("openSUSE Leap", func() {
 				Arch: "aarch64",
 			},
 		),
-		Entry("leap:16.0 x86_64", "x86_64", "16.0", "sles", "testdata/Leap-16.0-Minimal-VM.x86_64-Cloud.qcow2.sha256",
+		Entry("leap:16.0 x86_64", "x86_64", "16.0", "sles",
 			map[string]string{
 				common.DefaultInstancetypeEnv: "u1.medium",
 				common.DefaultPreferenceEnv:   "opensuse.leap",
@@ -134,7 +133,7 @@ var _ = Describe("openSUSE Leap", func() {
 				Arch: "x86_64",
 			},
 		),
-		Entry("leap:16.0 aarch64", "aarch64", "16.0", "sles", "testdata/Leap-16.0-Minimal-VM.aarch64-Cloud.qcow2.sha256",
+		Entry("leap:16.0 aarch64", "aarch64", "16.0", "sles",
 			nil,
 			&api.ArtifactDetails{
 				Checksum:          "efd9fe8009274134f5774ffbbf24d8421e482a14427a588cd3c75e28220a029c",
@@ -151,7 +150,7 @@ var _ = Describe("openSUSE Leap", func() {
 				Arch: "aarch64",
 			},
 		),
-		Entry("leap:16.0 s390x", "s390x", "16.0", "sles", "testdata/Leap-16.0-Minimal-VM.s390x-s390x-Cloud.qcow2.sha256",
+		Entry("leap:16.0 s390x", "s390x", "16.0", "sles",
 			nil,
 			&api.ArtifactDetails{
 				Checksum:          "647bc07f1c21b02f703f2545a5808247d3faaba61bf20f59997855d1bbc390b7",
@@ -110,14 +110,17 @@ func (t *microos) UserData(data *docs.UserData) string {
 func (t *microos) Tests() []api.ArtifactTest {
 	return []api.ArtifactTest{
 		tests.SSH,
+		tests.CloudInit,
+		// MicroOS is a rolling release, VERSION_ID is the snapshot date
+		tests.OSRelease(tests.OSIdentity{ID: "opensuse-microos", Arch: t.Arch}),
 	}
 }
 
-func New(arch string, envVariables map[string]string) *microos {
+func New(getter http.Getter, arch string, envVariables map[string]string) *microos {
 	return &microos{
 		Arch:         arch,
 		variant:      "openSUSE-MicroOS",
-		getter:       &http.HTTPGetter{},
+		getter:       getter,
 		envVariables: envVariables,
 	}
 }
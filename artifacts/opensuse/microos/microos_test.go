@@ -15,9 +15,8 @@ import (
 
 var _ = Describe("openSUSE MicroOS", func() {
 	DescribeTable("Inspect should be able to parse checksum files",
-		func(arch, mockFile string, envVariables map[string]string, details *api.ArtifactDetails, metadata *api.Metadata) {
-			c := New(arch, envVariables)
-			c.getter = testutil.NewMockGetter(mockFile)
+		func(arch string, envVariables map[string]string, details *api.ArtifactDetails, metadata *api.Metadata) {
+			c := New(testutil.NewFixtureGetter("testdata"), arch, envVariables)
 			got, err := c.Inspect()
 			Expect(err).NotTo(HaveOccurred())
 			Expect(got.ChecksumHash).ToNot(BeNil())
@@ -28,7 +27,7 @@ var _ = Describe("openSUSE MicroOS", func() {
 			Expect(got.Compression).To(Equal(details.Compression))
 			Expect(c.Metadata()).To(Equal(metadata))
 		},
-		Entry("microos:1 x86_64", "x86_64", "testdata/microos.SHA256SUM",
+		Entry("microos:1 x86_64", "x86_64",
 			map[string]string{
 				common.DefaultInstancetypeEnv: "u1.medium",
 				common.DefaultPreferenceEnv:   "opensuse.tumbleweed",
@@ -52,7 +51,7 @@ var _ = Describe("openSUSE MicroOS", func() {
 				Arch: "x86_64",
 			},
 		),
-		Entry("microos:1 s390x", "s390x", "testdata/microos-s390x.SHA256SUM",
+		Entry("microos:1 s390x", "s390x",
 			map[string]string{
 				common.DefaultInstancetypeEnv: "u1.medium",
 				common.DefaultPreferenceEnv:   "opensuse.tumbleweed",
@@ -101,15 +101,18 @@ func (t *tumbleweed) UserData(data *docs.UserData) string {
 func (t *tumbleweed) Tests() []api.ArtifactTest {
 	return []api.ArtifactTest{
 		tests.SSH,
+		tests.CloudInit,
+		// Tumbleweed is a rolling release, VERSION_ID is the snapshot date
+		tests.OSRelease(tests.OSIdentity{ID: "opensuse-tumbleweed", Arch: t.Arch}),
 	}
 }
 
-func New(arch string, envVariables map[string]string) *tumbleweed {
+func New(getter http.Getter, arch string, envVariables map[string]string) *tumbleweed {
 	return &tumbleweed{
 		Arch:         arch,
 		variant:      "openSUSE-Tumbleweed-Minimal-VM",
 		subVariant:   "Cloud",
-		getter:       &http.HTTPGetter{},
+		getter:       getter,
 		envVariables: envVariables,
 	}
 }
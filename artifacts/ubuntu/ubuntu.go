@@ -81,15 +81,17 @@ func (u *ubuntu) UserData(data *docs.UserData) string {
 func (u *ubuntu) Tests() []api.ArtifactTest {
 	return []api.ArtifactTest{
 		tests.SSH,
+		tests.CloudInit,
+		tests.OSRelease(tests.OSIdentity{ID: "ubuntu", VersionID: u.Version, Arch: u.Arch}),
 	}
 }
 
-func New(release, arch string, envVariables map[string]string) *ubuntu {
+func New(getter http.Getter, release, arch string, envVariables map[string]string) *ubuntu {
 	return &ubuntu{
 		Version:      release,
 		Arch:         arch,
 		Variant:      fmt.Sprintf("ubuntu-%v-server-cloudimg-%s.img", release, architecture.GetImageArchitecture(arch)),
-		getter:       &http.HTTPGetter{},
+		getter:       getter,
 		EnvVariables: envVariables,
 	}
 }
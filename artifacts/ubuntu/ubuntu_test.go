@@ -14,9 +14,8 @@ import (
 
 var _ = Describe("Ubuntu", func() {
 	DescribeTable("Inspect should be able to parse checksum files",
-		func(release, arch, mockFile string, details *api.ArtifactDetails, envVariables map[string]string, metadata *api.Metadata) {
-			c := New(release, arch, envVariables)
-			c.getter = testutil.NewMockGetter(mockFile)
+		func(release, arch string, details *api.ArtifactDetails, envVariables map[string]string, metadata *api.Metadata) {
+			c := New(testutil.NewFixtureGetter("testdata"), release, arch, envVariables)
 			got, err := c.Inspect()
 			Expect(err).NotTo(HaveOccurred())
 			Expect(got.ChecksumHash).ToNot(BeNil())
@@ -28,7 +27,7 @@ var _ = Describe("Ubuntu", func() {
 			Expect(c.Metadata()).To(Equal(metadata))
 			Expect(err).NotTo(HaveOccurred())
 		},
-		Entry("ubuntu:22.04 x86_64", "22.04", "x86_64", "testdata/SHA256SUM",
+		Entry("ubuntu:22.04 x86_64", "22.04", "x86_64",
 			&api.ArtifactDetails{
 				Checksum:          "de5e632e17b8965f2baf4ea6d2b824788e154d9a65df4fd419ec4019898e15cd",
 				DownloadURL:       "https://cloud-images.ubuntu.com/releases/22.04/release/ubuntu-22.04-server-cloudimg-amd64.img",
@@ -52,7 +51,7 @@ var _ = Describe("Ubuntu", func() {
 				Arch: "x86_64",
 			},
 		),
-		Entry("ubuntu:22.04 aarch64", "22.04", "aarch64", "testdata/SHA256SUM",
+		Entry("ubuntu:22.04 aarch64", "22.04", "aarch64",
 			&api.ArtifactDetails{
 				Checksum:          "66224c7fed99ff5a5539eda406c87bbfefe8af6ff6b47d92df3187832b5b5d4f",
 				DownloadURL:       "https://cloud-images.ubuntu.com/releases/22.04/release/ubuntu-22.04-server-cloudimg-arm64.img",
@@ -76,7 +75,7 @@ var _ = Describe("Ubuntu", func() {
 				Arch: "aarch64",
 			},
 		),
-		Entry("ubuntu:22.04 s390x", "22.04", "s390x", "testdata/SHA256SUM",
+		Entry("ubuntu:22.04 s390x", "22.04", "s390x",
 			&api.ArtifactDetails{
 				Checksum:          "192c18a58917622e12a3bb6aaf246fcc6a76d9562eb9f49d34df81fbc59610af",
 				DownloadURL:       "https://cloud-images.ubuntu.com/releases/22.04/release/ubuntu-22.04-server-cloudimg-s390x.img",
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"kubevirt.io/containerdisks/pkg/repository"
+)
+
+// NewAuthConfig creates the registry credential configuration from the command line options.
+func NewAuthConfig(o *AuthOptions) (repository.AuthConfig, error) {
+	auth := repository.AuthConfig{
+		AuthFile: o.AuthFile,
+	}
+
+	if o.Username != "" || o.PasswordFile != "" {
+		creds, err := repository.NewCredentials(o.Username, o.PasswordFile)
+		if err != nil {
+			return auth, err
+		}
+		auth.Credentials = creds
+	}
+
+	for registry, value := range o.RegistryCredentials {
+		username, passwordFile, ok := strings.Cut(value, ":")
+		if !ok {
+			return auth, fmt.Errorf("credentials for %q are not in the format <username>:<password-file>", registry)
+		}
+		creds, err := repository.NewCredentials(username, passwordFile)
+		if err != nil {
+			return auth, fmt.Errorf("error loading credentials for %q: %v", registry, err)
+		}
+		if auth.Registries == nil {
+			auth.Registries = map[string]repository.Credentials{}
+		}
+		auth.Registries[registry] = *creds
+	}
+
+	return auth, nil
+}
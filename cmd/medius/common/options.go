@@ -1,14 +1,39 @@
 package common
 
+import (
+	"kubevirt.io/containerdisks/pkg/http"
+	"kubevirt.io/containerdisks/pkg/network"
+	"kubevirt.io/containerdisks/pkg/repository"
+	"kubevirt.io/containerdisks/pkg/tests"
+)
+
 type Options struct {
 	AllowInsecureRegistry bool
+	AuthOptions           AuthOptions
 	DryRun                bool
 	Focus                 string
 	ImagesOptions         ImagesOptions
+	MetadataCacheDir      string
+	Network               *network.Config
+	NetworkOptions        network.Options
 	PublishDocsOptions    PublishDocsOptions
 	PublishImagesOptions  PublishImageOptions
 	PromoteImageOptions   PromoteImageOptions
-	VerifyImagesOptions   VerifyImageOptions
+	PruneImageOptions     PruneImageOptions
+	RegistryAuth          repository.AuthConfig
+	ReportOptions         ReportOptions
+	// Upstream downloads the upstream files of the containerdisks, it is configured by setup.
+	Upstream            http.HTTPGetter
+	UpstreamMirror      string
+	VerifyImagesOptions VerifyImageOptions
+}
+
+type AuthOptions struct {
+	AuthFile     string
+	Username     string
+	PasswordFile string
+	// RegistryCredentials maps registries or repositories to "<username>:<password-file>".
+	RegistryCredentials map[string]string
 }
 
 type ImagesOptions struct {
@@ -17,13 +42,48 @@ type ImagesOptions struct {
 }
 
 type PromoteImageOptions struct {
-	SourceRegistry string
-	TargetRegistry string
+	SourceRegistry   string
+	TargetRegistries []string
+	TargetsFile      string
+	ArchPolicy       string
+}
+
+// PromoteTargetsConfig is the content of the file passed with --targets-file.
+type PromoteTargetsConfig struct {
+	Targets []PromoteTarget `json:"targets"`
+}
+
+// PromoteTarget describes a registry containerdisks are promoted to.
+type PromoteTarget struct {
+	// Registry is the registry and organization to promote to, e.g. "quay.io/containerdisks".
+	Registry string `json:"registry"`
+	// AuthFile is a containers-auth.json(5) file with credentials for the registry.
+	// If set, it replaces the global --authfile, --username and --password-file options for this target.
+	AuthFile string `json:"authFile,omitempty"`
+	// InsecureSkipTLS overrides the global --insecure-skip-tls flag for this target.
+	InsecureSkipTLS *bool `json:"insecureSkipTLS,omitempty"`
+	// DryRun overrides the global --dry-run flag for this target.
+	DryRun *bool `json:"dryRun,omitempty"`
+}
+
+type ReportOptions struct {
+	// PreviousResultsFile is the results file of the run to compare the boot metrics with.
+	PreviousResultsFile string
+	// RegressionThreshold is the increase of a boot metric in percent which is considered a regression.
+	RegressionThreshold int
+}
+
+type PruneImageOptions struct {
+	Registry string
+	KeepLast int
+	KeepDays int
 }
 
 type PublishDocsOptions struct {
 	Registry  string
 	TokenFile string
+	// VerifyResultsFile is a results file of verify, the verified VM configurations are listed in the docs if set.
+	VerifyResultsFile string
 }
 
 type PublishImageOptions struct {
@@ -31,12 +91,50 @@ type PublishImageOptions struct {
 	NoFail         bool
 	SourceRegistry string
 	TargetRegistry string
+	// DownloadConnections is the number of parallel range requests per upstream download.
+	DownloadConnections int
+	// DownloadConnectionsPerHost overrides DownloadConnections for specific upstream hosts.
+	DownloadConnectionsPerHost map[string]int
+	// CacheDir is the directory of the download cache, the cache is disabled if empty.
+	CacheDir string
+	// CacheMaxSize is the maximum size of the download cache as quantity, e.g. "50Gi".
+	CacheMaxSize string
+	// MirrorOverrides maps containerdisk names to mirrors which are tried before the upstream URLs.
+	MirrorOverrides map[string]string
 }
 
 type VerifyImageOptions struct {
-	Registry           string
-	Namespace          string
-	NoFail             bool
-	Timeout            int
-	TargetArchitecture string
+	Registry string
+	// Image is a containerdisk reference which is verified instead of the containerdisks in the results file.
+	Image string
+	// Artifact is the artifact used to verify Image as <name>:<version>, it is derived from Image if empty.
+	Artifact  string
+	Namespace string
+	NoFail    bool
+	Timeout   int
+	// TargetArchitectures are the architectures to verify containerdisks on, e.g. "amd64".
+	TargetArchitectures []string
+	// BootSource is the volume VMs boot from, "containerdisk" or "datavolume".
+	BootSource string
+	// DataVolumeSize is the size of the DataVolumes VMs boot from as quantity, e.g. "20Gi".
+	DataVolumeSize string
+	// StorageClass is the storage class of the DataVolumes VMs boot from, the default is used if empty.
+	StorageClass string
+	// ArtifactsDir is the directory diagnostics of failed verifications are stored in, nothing is stored if empty.
+	ArtifactsDir string
+	// PreviousResultsFile is the results file of a previous run, boot metric regressions compared to it fail verify.
+	PreviousResultsFile string
+	// RegressionThreshold is the increase of a boot metric in percent which is considered a regression.
+	RegressionThreshold int
+	// Firmwares are the firmwares of the VM configurations verified in addition to the VM of the artifact,
+	// "default", "bios", "efi" or "secureboot".
+	Firmwares []string
+	// DiskBuses are the disk buses of the additional VM configurations, "default", "virtio" or "sata".
+	DiskBuses []string
+	// Instancetype additionally verifies all configurations with the default instancetype and preference.
+	Instancetype bool
+	// TestSpecFiles are YAML files with declarative guest tests, which run in addition to the artifact tests.
+	TestSpecFiles []string
+	// TestSpecs are the tests loaded from TestSpecFiles.
+	TestSpecs []tests.Spec
 }
@@ -18,6 +18,7 @@ import (
 	"kubevirt.io/containerdisks/pkg/api"
 	"kubevirt.io/containerdisks/pkg/common"
 	"kubevirt.io/containerdisks/pkg/docs"
+	"kubevirt.io/containerdisks/pkg/http"
 )
 
 type Entry struct {
@@ -27,164 +28,167 @@ type Entry struct {
 	SkipWhenNotFocused bool
 }
 
-var staticRegistry = []Entry{
-	{
-		Artifacts: []api.Artifact{
-			almalinux.New("10", "x86_64", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.10")),
-			almalinux.New("10", "aarch64", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.10")),
-			almalinux.New("10", "s390x", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.10")),
-		},
-		UseForDocs: true,
-	},
-	{
-		Artifacts: []api.Artifact{
-			almalinux.New("9", "x86_64", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.9")),
-			almalinux.New("9", "aarch64", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.9")),
-			almalinux.New("9", "s390x", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.9")),
-		},
-		UseForDocs: true,
-	},
-	{
-		Artifacts: []api.Artifact{
-			centosstream.New("10", "x86_64", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream10")),
-			centosstream.New("10", "aarch64", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream10")),
-			centosstream.New("10", "s390x", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream10")),
-		},
-		UseForDocs: true,
-	},
-	{
-		Artifacts: []api.Artifact{
-			centosstream.New("9", "x86_64", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream9")),
-			centosstream.New("9", "aarch64", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream9")),
-			centosstream.New("9", "s390x", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream9")),
-		},
-		UseForDocs: true,
-	},
-	{
-		Artifacts: []api.Artifact{
-			ubuntu.New("26.04", "x86_64", defaultEnvVariables("u1.medium", "ubuntu")),
-			ubuntu.New("26.04", "aarch64", defaultEnvVariables("u1.medium", "ubuntu")),
-			ubuntu.New("26.04", "s390x", defaultEnvVariables("u1.medium", "ubuntu")),
-		},
-		UseForDocs: true,
-	},
-	{
-		Artifacts: []api.Artifact{
-			ubuntu.New("25.04", "x86_64", defaultEnvVariables("u1.medium", "ubuntu")),
-			ubuntu.New("25.04", "aarch64", defaultEnvVariables("u1.medium", "ubuntu")),
-			ubuntu.New("25.04", "s390x", defaultEnvVariables("u1.medium", "ubuntu")),
-		},
-		UseForDocs: false,
-	},
-	{
-		Artifacts: []api.Artifact{
-			ubuntu.New("24.04", "x86_64", defaultEnvVariables("u1.medium", "ubuntu")),
-			ubuntu.New("24.04", "aarch64", defaultEnvVariables("u1.medium", "ubuntu")),
-			ubuntu.New("24.04", "s390x", defaultEnvVariables("u1.medium", "ubuntu")),
-		},
-		UseForDocs: false,
-	},
-	{
-		Artifacts: []api.Artifact{
-			ubuntu.New("22.04", "x86_64", defaultEnvVariables("u1.medium", "ubuntu")),
-			ubuntu.New("22.04", "aarch64", defaultEnvVariables("u1.medium", "ubuntu")),
-			ubuntu.New("22.04", "s390x", defaultEnvVariables("u1.medium", "ubuntu")),
-		},
-		UseForDocs: false,
-	},
-	{
-		Artifacts: []api.Artifact{
-			ubuntu.New("20.04", "x86_64", defaultEnvVariables("u1.medium", "ubuntu")),
-			ubuntu.New("20.04", "aarch64", defaultEnvVariables("u1.medium", "ubuntu")),
-			ubuntu.New("20.04", "s390x", defaultEnvVariables("u1.medium", "ubuntu")),
-		},
-		UseForDocs: false,
-	},
-	{
-		Artifacts: []api.Artifact{
-			tumbleweed.New("x86_64", defaultEnvVariables("u1.medium", "opensuse.tumbleweed")),
-			tumbleweed.New("s390x", defaultEnvVariables("u1.medium", "opensuse.tumbleweed")),
-		},
-		UseForDocs: true,
-	},
-	{
-		Artifacts: []api.Artifact{
-			microos.New("x86_64", defaultEnvVariables("u1.medium", "opensuse.tumbleweed")),
-			microos.New("s390x", defaultEnvVariables("u1.medium", "opensuse.tumbleweed")),
-		},
-		UseForDocs: true,
-	},
-	{
-		Artifacts: []api.Artifact{
-			leap.New("x86_64", "16.0", "sles", defaultEnvVariables("u1.medium", "opensuse.leap")),
-			leap.New("aarch64", "16.0", "sles", defaultEnvVariables("u1.medium", "opensuse.leap")),
-			leap.New("s390x", "16.0", "sles", defaultEnvVariables("u1.medium", "opensuse.leap")),
-		},
-		UseForDocs: true,
-	},
-	{
-		Artifacts: []api.Artifact{
-			leap.New("x86_64", "15.6", "opensuse", defaultEnvVariables("u1.medium", "opensuse.leap")),
-			leap.New("aarch64", "15.6", "opensuse", defaultEnvVariables("u1.medium", "opensuse.leap")),
-		},
-	},
-	{
-		Artifacts: []api.Artifact{
-			leap.New("x86_64", "15.5", "opensuse", defaultEnvVariables("u1.medium", "opensuse.leap")),
-			leap.New("aarch64", "15.5", "opensuse", defaultEnvVariables("u1.medium", "opensuse.leap")),
-		},
-	},
-	{
-		Artifacts: []api.Artifact{
-			debian.New("11", "bullseye", "x86_64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
-			debian.New("11", "bullseye", "aarch64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
-		},
-	},
-	{
-		Artifacts: []api.Artifact{
-			debian.New("12", "bookworm", "x86_64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
-			debian.New("12", "bookworm", "aarch64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
-		},
-	},
-	{
-		Artifacts: []api.Artifact{
-			debian.New("13", "trixie", "x86_64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
-			debian.New("13", "trixie", "aarch64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
-		},
-		UseForDocs:   true,
-		UseForLatest: true,
-	},
-	// for testing only
-	{
-		Artifacts: []api.Artifact{
-			generic.New(
-				&api.ArtifactDetails{
-					Checksum:          "cc704ab14342c1c8a8d91b66a7fc611d921c8b8f1aaf4695f9d6463d913fa8d1",
-					ChecksumHash:      sha256.New,
-					DownloadURL:       "https://download.cirros-cloud.net/0.6.1/cirros-0.6.1-x86_64-disk.img",
-					ImageArchitecture: "amd64",
-				},
-				&api.Metadata{
-					Name:    "cirros",
-					Version: "6.1",
-				},
-			),
-			generic.New(
-				&api.ArtifactDetails{
-					Checksum:          "db9420c481c11dee17860aa46fb1a3efa05fa4fb152726d6344e24da03cb0ccf",
-					ChecksumHash:      sha256.New,
-					DownloadURL:       "https://download.cirros-cloud.net/0.6.1/cirros-0.6.1-aarch64-disk.img",
-					ImageArchitecture: "arm64",
-				},
-				&api.Metadata{
-					Name:    "cirros",
-					Version: "6.1",
-				},
-			),
-		},
-		SkipWhenNotFocused: true,
-		UseForDocs:         false,
-	},
+// staticRegistry returns the containerdisks which are not gathered from upstream release lists.
+func staticRegistry(getter http.Getter) []Entry { //nolint:funlen // a single table of all static containerdisks
+	return []Entry{
+		{
+			Artifacts: []api.Artifact{
+				almalinux.New(getter, "10", "x86_64", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.10")),
+				almalinux.New(getter, "10", "aarch64", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.10")),
+				almalinux.New(getter, "10", "s390x", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.10")),
+			},
+			UseForDocs: true,
+		},
+		{
+			Artifacts: []api.Artifact{
+				almalinux.New(getter, "9", "x86_64", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.9")),
+				almalinux.New(getter, "9", "aarch64", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.9")),
+				almalinux.New(getter, "9", "s390x", &docs.UserData{Username: "almalinux"}, defaultEnvVariables("u1.medium", "rhel.9")),
+			},
+			UseForDocs: true,
+		},
+		{
+			Artifacts: []api.Artifact{
+				centosstream.New(getter, "10", "x86_64", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream10")),
+				centosstream.New(getter, "10", "aarch64", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream10")),
+				centosstream.New(getter, "10", "s390x", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream10")),
+			},
+			UseForDocs: true,
+		},
+		{
+			Artifacts: []api.Artifact{
+				centosstream.New(getter, "9", "x86_64", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream9")),
+				centosstream.New(getter, "9", "aarch64", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream9")),
+				centosstream.New(getter, "9", "s390x", &docs.UserData{Username: "cloud-user"}, defaultEnvVariables("u1.medium", "centos.stream9")),
+			},
+			UseForDocs: true,
+		},
+		{
+			Artifacts: []api.Artifact{
+				ubuntu.New(getter, "26.04", "x86_64", defaultEnvVariables("u1.medium", "ubuntu")),
+				ubuntu.New(getter, "26.04", "aarch64", defaultEnvVariables("u1.medium", "ubuntu")),
+				ubuntu.New(getter, "26.04", "s390x", defaultEnvVariables("u1.medium", "ubuntu")),
+			},
+			UseForDocs: true,
+		},
+		{
+			Artifacts: []api.Artifact{
+				ubuntu.New(getter, "25.04", "x86_64", defaultEnvVariables("u1.medium", "ubuntu")),
+				ubuntu.New(getter, "25.04", "aarch64", defaultEnvVariables("u1.medium", "ubuntu")),
+				ubuntu.New(getter, "25.04", "s390x", defaultEnvVariables("u1.medium", "ubuntu")),
+			},
+			UseForDocs: false,
+		},
+		{
+			Artifacts: []api.Artifact{
+				ubuntu.New(getter, "24.04", "x86_64", defaultEnvVariables("u1.medium", "ubuntu")),
+				ubuntu.New(getter, "24.04", "aarch64", defaultEnvVariables("u1.medium", "ubuntu")),
+				ubuntu.New(getter, "24.04", "s390x", defaultEnvVariables("u1.medium", "ubuntu")),
+			},
+			UseForDocs: false,
+		},
+		{
+			Artifacts: []api.Artifact{
+				ubuntu.New(getter, "22.04", "x86_64", defaultEnvVariables("u1.medium", "ubuntu")),
+				ubuntu.New(getter, "22.04", "aarch64", defaultEnvVariables("u1.medium", "ubuntu")),
+				ubuntu.New(getter, "22.04", "s390x", defaultEnvVariables("u1.medium", "ubuntu")),
+			},
+			UseForDocs: false,
+		},
+		{
+			Artifacts: []api.Artifact{
+				ubuntu.New(getter, "20.04", "x86_64", defaultEnvVariables("u1.medium", "ubuntu")),
+				ubuntu.New(getter, "20.04", "aarch64", defaultEnvVariables("u1.medium", "ubuntu")),
+				ubuntu.New(getter, "20.04", "s390x", defaultEnvVariables("u1.medium", "ubuntu")),
+			},
+			UseForDocs: false,
+		},
+		{
+			Artifacts: []api.Artifact{
+				tumbleweed.New(getter, "x86_64", defaultEnvVariables("u1.medium", "opensuse.tumbleweed")),
+				tumbleweed.New(getter, "s390x", defaultEnvVariables("u1.medium", "opensuse.tumbleweed")),
+			},
+			UseForDocs: true,
+		},
+		{
+			Artifacts: []api.Artifact{
+				microos.New(getter, "x86_64", defaultEnvVariables("u1.medium", "opensuse.tumbleweed")),
+				microos.New(getter, "s390x", defaultEnvVariables("u1.medium", "opensuse.tumbleweed")),
+			},
+			UseForDocs: true,
+		},
+		{
+			Artifacts: []api.Artifact{
+				leap.New(getter, "x86_64", "16.0", "sles", defaultEnvVariables("u1.medium", "opensuse.leap")),
+				leap.New(getter, "aarch64", "16.0", "sles", defaultEnvVariables("u1.medium", "opensuse.leap")),
+				leap.New(getter, "s390x", "16.0", "sles", defaultEnvVariables("u1.medium", "opensuse.leap")),
+			},
+			UseForDocs: true,
+		},
+		{
+			Artifacts: []api.Artifact{
+				leap.New(getter, "x86_64", "15.6", "opensuse", defaultEnvVariables("u1.medium", "opensuse.leap")),
+				leap.New(getter, "aarch64", "15.6", "opensuse", defaultEnvVariables("u1.medium", "opensuse.leap")),
+			},
+		},
+		{
+			Artifacts: []api.Artifact{
+				leap.New(getter, "x86_64", "15.5", "opensuse", defaultEnvVariables("u1.medium", "opensuse.leap")),
+				leap.New(getter, "aarch64", "15.5", "opensuse", defaultEnvVariables("u1.medium", "opensuse.leap")),
+			},
+		},
+		{
+			Artifacts: []api.Artifact{
+				debian.New(getter, "11", "bullseye", "x86_64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
+				debian.New(getter, "11", "bullseye", "aarch64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
+			},
+		},
+		{
+			Artifacts: []api.Artifact{
+				debian.New(getter, "12", "bookworm", "x86_64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
+				debian.New(getter, "12", "bookworm", "aarch64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
+			},
+		},
+		{
+			Artifacts: []api.Artifact{
+				debian.New(getter, "13", "trixie", "x86_64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
+				debian.New(getter, "13", "trixie", "aarch64", &docs.UserData{Username: "debian"}, defaultEnvVariables("u1.medium", "debian")),
+			},
+			UseForDocs:   true,
+			UseForLatest: true,
+		},
+		// for testing only
+		{
+			Artifacts: []api.Artifact{
+				generic.New(
+					&api.ArtifactDetails{
+						Checksum:          "cc704ab14342c1c8a8d91b66a7fc611d921c8b8f1aaf4695f9d6463d913fa8d1",
+						ChecksumHash:      sha256.New,
+						DownloadURL:       "https://download.cirros-cloud.net/0.6.1/cirros-0.6.1-x86_64-disk.img",
+						ImageArchitecture: "amd64",
+					},
+					&api.Metadata{
+						Name:    "cirros",
+						Version: "6.1",
+					},
+				),
+				generic.New(
+					&api.ArtifactDetails{
+						Checksum:          "db9420c481c11dee17860aa46fb1a3efa05fa4fb152726d6344e24da03cb0ccf",
+						ChecksumHash:      sha256.New,
+						DownloadURL:       "https://download.cirros-cloud.net/0.6.1/cirros-0.6.1-aarch64-disk.img",
+						ImageArchitecture: "arm64",
+					},
+					&api.Metadata{
+						Name:    "cirros",
+						Version: "6.1",
+					},
+				),
+			},
+			SkipWhenNotFocused: true,
+			UseForDocs:         false,
+		},
+	}
 }
 
 func gatherArtifacts(registry *[]Entry, gatherers []api.ArtifactsGatherer) {
@@ -216,11 +220,11 @@ func defaultEnvVariables(defaultInstancetype, defaultPreference string) map[stri
 	}
 }
 
-func NewRegistry() []Entry {
-	registry := make([]Entry, len(staticRegistry))
-	copy(registry, staticRegistry)
+// NewRegistry returns all containerdisks, their upstream files are downloaded with getter.
+func NewRegistry(getter http.Getter) []Entry {
+	registry := staticRegistry(getter)
 
-	gatherers := []api.ArtifactsGatherer{fedora.NewGatherer()}
+	gatherers := []api.ArtifactsGatherer{fedora.NewGatherer(getter)}
 	gatherArtifacts(&registry, gatherers)
 
 	return registry
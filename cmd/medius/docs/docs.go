@@ -3,9 +3,13 @@ package docs
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"os"
 	"path"
+	"slices"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -35,6 +39,8 @@ func NewPublishDocsCommand(options *common.Options) *cobra.Command {
 		options.PublishDocsOptions.Registry, "target registry for the containerdisks")
 	publishCmd.Flags().StringVar(&options.PublishDocsOptions.TokenFile, "quay-token-file",
 		options.PublishDocsOptions.TokenFile, "quay.io oauth token file")
+	publishCmd.Flags().StringVar(&options.PublishDocsOptions.VerifyResultsFile, "verify-results-file",
+		options.PublishDocsOptions.VerifyResultsFile, "results file of verify, the verified VM configurations are listed if set")
 
 	err := publishCmd.MarkFlagRequired("quay-token-file")
 	if err != nil {
@@ -53,8 +59,19 @@ func run(options *common.Options) error {
 		return err
 	}
 
-	client := quay.NewQuayClient(options.PublishDocsOptions.TokenFile, quayOrg)
-	registry := common.NewRegistry()
+	results := map[string]api.ArtifactResult{}
+	if options.PublishDocsOptions.VerifyResultsFile != "" {
+		data, err := os.ReadFile(options.PublishDocsOptions.VerifyResultsFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &results); err != nil {
+			return fmt.Errorf("error parsing %s: %v", options.PublishDocsOptions.VerifyResultsFile, err)
+		}
+	}
+
+	client := quay.NewQuayClient(options.PublishDocsOptions.TokenFile, quayOrg, options.Network.Client())
+	registry := common.NewRegistry(&options.Upstream)
 	for i, p := range registry {
 		if common.ShouldSkip(options.Focus, &registry[i]) || !p.UseForDocs {
 			continue
@@ -71,7 +88,7 @@ func run(options *common.Options) error {
 		log := common.Logger(artifact)
 		name := artifact.Metadata().Name
 
-		description, err := createDescription(artifact, options.PublishDocsOptions.Registry)
+		description, err := createDescription(artifact, options.PublishDocsOptions.Registry, results[artifact.Metadata().Describe()])
 		if err != nil {
 			success = false
 			log.Errorf("error marshaling example for %q: %v", name, err)
@@ -130,7 +147,7 @@ func getPreferredArtifact(artifacts []api.Artifact) (api.Artifact, error) {
 	return artifacts[0], nil
 }
 
-func createDescription(artifact api.Artifact, registry string) (string, error) {
+func createDescription(artifact api.Artifact, registry string, verifyResult api.ArtifactResult) (string, error) {
 	metadata := artifact.Metadata()
 	image := path.Join(registry, metadata.Describe())
 	vm := artifact.VM(
@@ -145,12 +162,13 @@ func createDescription(artifact api.Artifact, registry string) (string, error) {
 	}
 
 	data := &docs.TemplateData{
-		Name:         metadata.Name,
-		Description:  metadata.Description,
-		Example:      string(example),
-		Image:        image,
-		Instancetype: metadata.EnvVariables[pkgcommon.DefaultInstancetypeEnv],
-		Preference:   metadata.EnvVariables[pkgcommon.DefaultPreferenceEnv],
+		Name:           metadata.Name,
+		Description:    metadata.Description,
+		Example:        string(example),
+		Image:          image,
+		Instancetype:   metadata.EnvVariables[pkgcommon.DefaultInstancetypeEnv],
+		Preference:     metadata.EnvVariables[pkgcommon.DefaultPreferenceEnv],
+		Configurations: verifiedConfigurations(&verifyResult),
 	}
 
 	var result bytes.Buffer
@@ -160,3 +178,26 @@ func createDescription(artifact api.Artifact, registry string) (string, error) {
 
 	return result.String(), nil
 }
+
+// verifiedConfigurations returns the verified VM configurations of all architectures of result.
+// The unchanged VM of the artifact is listed first.
+func verifiedConfigurations(result *api.ArtifactResult) []docs.VerifiedConfiguration {
+	var configurations []docs.VerifiedConfiguration
+	for _, arch := range slices.Sorted(maps.Keys(result.Architectures)) {
+		archResult := result.Architectures[arch]
+		configurations = append(configurations, docs.VerifiedConfiguration{
+			Architecture:  arch,
+			Configuration: api.DefaultConfiguration,
+			Verified:      archResult.Verified,
+		})
+		for _, name := range slices.Sorted(maps.Keys(archResult.Configurations)) {
+			configurations = append(configurations, docs.VerifiedConfiguration{
+				Architecture:  arch,
+				Configuration: name,
+				Verified:      archResult.Configurations[name].Verified,
+			})
+		}
+	}
+
+	return configurations
+}
@@ -27,7 +27,7 @@ type workerResult struct {
 func spawnWorkers(ctx context.Context, o *common.Options,
 	fn func(*common.Entry) (*api.ArtifactResult, error),
 ) (matched bool, resultsChan chan workerResult, err error) {
-	registry := common.NewRegistry()
+	registry := common.NewRegistry(&o.Upstream)
 	count := len(registry)
 	errChan := make(chan error, count)
 	jobChan := make(chan *common.Entry, count)
@@ -0,0 +1,82 @@
+package images
+
+import (
+	"fmt"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	v1 "kubevirt.io/api/core/v1"
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	"kubevirt.io/containerdisks/cmd/medius/common"
+)
+
+const (
+	// BootSourceContainerDisk boots VMs from an ephemeral containerDisk volume.
+	BootSourceContainerDisk = "containerdisk"
+	// BootSourceDataVolume imports the containerdisk into a DataVolume and boots VMs from it,
+	// like users do with virtctl create vm --volume-import.
+	BootSourceDataVolume = "datavolume"
+)
+
+// dataVolumeSize returns the size of DataVolumes VMs are booted from, or zero if VMs boot from containerDisks.
+func dataVolumeSize(o *common.VerifyImageOptions) (resource.Quantity, error) {
+	switch o.BootSource {
+	case BootSourceContainerDisk:
+		return resource.Quantity{}, nil
+	case BootSourceDataVolume:
+		size, err := resource.ParseQuantity(o.DataVolumeSize)
+		if err != nil {
+			return resource.Quantity{}, fmt.Errorf("invalid DataVolume size %q: %v", o.DataVolumeSize, err)
+		}
+		if size.Sign() <= 0 {
+			return resource.Quantity{}, fmt.Errorf("invalid DataVolume size %q: must be positive", o.DataVolumeSize)
+		}
+		return size, nil
+	default:
+		return resource.Quantity{}, fmt.Errorf("unknown boot source %q", o.BootSource)
+	}
+}
+
+// withDataVolume replaces the containerDisk volume of vm with a DataVolume of the given size,
+// which imports imgRef for the given image architecture from the registry.
+func withDataVolume(vm *v1.VirtualMachine, imgRef, arch string, size resource.Quantity, storageClass string) error {
+	volumes := vm.Spec.Template.Spec.Volumes
+	for i := range volumes {
+		if volumes[i].ContainerDisk == nil {
+			continue
+		}
+
+		dataVolume := v1.DataVolumeTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: vm.Name + "-" + volumes[i].Name,
+			},
+			Spec: cdiv1beta1.DataVolumeSpec{
+				Source: &cdiv1beta1.DataVolumeSource{
+					Registry: &cdiv1beta1.DataVolumeSourceRegistry{
+						URL:      ptr.To("docker://" + imgRef),
+						Platform: &cdiv1beta1.PlatformOptions{Architecture: arch},
+					},
+				},
+				Storage: &cdiv1beta1.StorageSpec{
+					Resources: k8sv1.VolumeResourceRequirements{
+						Requests: k8sv1.ResourceList{k8sv1.ResourceStorage: size},
+					},
+				},
+			},
+		}
+		if storageClass != "" {
+			dataVolume.Spec.Storage.StorageClassName = ptr.To(storageClass)
+		}
+		vm.Spec.DataVolumeTemplates = append(vm.Spec.DataVolumeTemplates, dataVolume)
+
+		volumes[i].VolumeSource = v1.VolumeSource{
+			DataVolume: &v1.DataVolumeSource{Name: dataVolume.Name},
+		}
+		return nil
+	}
+
+	return fmt.Errorf("VM %s has no containerDisk volume", vm.Name)
+}
@@ -0,0 +1,61 @@
+package images
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "kubevirt.io/api/core/v1"
+
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/docs"
+)
+
+var _ = Describe("DataVolume", func() {
+	DescribeTable("dataVolumeSize should validate the options",
+		func(bootSource, size string, expected resource.Quantity, expectedErr string) {
+			quantity, err := dataVolumeSize(&common.VerifyImageOptions{BootSource: bootSource, DataVolumeSize: size})
+			if expectedErr != "" {
+				Expect(err).To(MatchError(expectedErr))
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+			Expect(quantity.Cmp(expected)).To(BeZero())
+		},
+		Entry("containerdisk", BootSourceContainerDisk, "20Gi", resource.Quantity{}, ""),
+		Entry("datavolume", BootSourceDataVolume, "20Gi", resource.MustParse("20Gi"), ""),
+		Entry("invalid size", BootSourceDataVolume, "big", resource.Quantity{},
+			`invalid DataVolume size "big": quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'`),
+		Entry("zero size", BootSourceDataVolume, "0", resource.Quantity{}, `invalid DataVolume size "0": must be positive`),
+		Entry("unknown boot source", "pvc", "20Gi", resource.Quantity{}, `unknown boot source "pvc"`),
+	)
+
+	It("withDataVolume should replace the containerDisk with a DataVolume", func() {
+		vm := docs.NewVM("fedora-abcde", "registry:5000/fedora:43", docs.WithCloudInitNoCloud(""))
+
+		Expect(withDataVolume(vm, "registry:5000/fedora:43", "arm64", resource.MustParse("20Gi"), "local")).To(Succeed())
+
+		Expect(vm.Spec.DataVolumeTemplates).To(HaveLen(1))
+		dataVolume := vm.Spec.DataVolumeTemplates[0]
+		Expect(dataVolume.Name).To(Equal("fedora-abcde-containerdisk"))
+		Expect(*dataVolume.Spec.Source.Registry.URL).To(Equal("docker://registry:5000/fedora:43"))
+		Expect(dataVolume.Spec.Source.Registry.Platform.Architecture).To(Equal("arm64"))
+		Expect(dataVolume.Spec.Storage.Resources.Requests).To(HaveKeyWithValue(k8sv1.ResourceStorage, resource.MustParse("20Gi")))
+		Expect(*dataVolume.Spec.Storage.StorageClassName).To(Equal("local"))
+
+		Expect(vm.Spec.Template.Spec.Volumes).To(ContainElement(v1.Volume{
+			Name:         "containerdisk",
+			VolumeSource: v1.VolumeSource{DataVolume: &v1.DataVolumeSource{Name: "fedora-abcde-containerdisk"}},
+		}))
+		for _, volume := range vm.Spec.Template.Spec.Volumes {
+			Expect(volume.ContainerDisk).To(BeNil())
+		}
+	})
+
+	It("withDataVolume should fail for VMs without containerDisk", func() {
+		vm := docs.NewVM("fedora-abcde", "registry:5000/fedora:43")
+		vm.Spec.Template.Spec.Volumes = nil
+		Expect(withDataVolume(vm, "registry:5000/fedora:43", "amd64", resource.MustParse("20Gi"), "")).To(
+			MatchError("VM fedora-abcde has no containerDisk volume"))
+	})
+})
@@ -0,0 +1,188 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "kubevirt.io/api/core/v1"
+	kvirtcli "kubevirt.io/client-go/kubecli"
+	"sigs.k8s.io/yaml"
+)
+
+// serialConsoleContainer is the virt-launcher container which logs the serial console of the guest.
+const serialConsoleContainer = "guest-console-log"
+
+// guestAgentInfo is the information reported by the guest agent.
+type guestAgentInfo struct {
+	OSInfo      *v1.VirtualMachineInstanceGuestAgentInfo  `json:"osInfo,omitempty"`
+	Users       *v1.VirtualMachineInstanceGuestOSUserList `json:"users,omitempty"`
+	Filesystems *v1.VirtualMachineInstanceFileSystemList  `json:"filesystems,omitempty"`
+}
+
+// diagnostics stores files describing the state of a VM in a directory.
+// Errors are recorded, so that as much as possible is collected.
+type diagnostics struct {
+	dir  string
+	errs []error
+}
+
+func (d *diagnostics) addErr(what string, err error) {
+	d.errs = append(d.errs, fmt.Errorf("failed to collect %s: %w", what, err))
+}
+
+func (d *diagnostics) write(name string, data []byte) {
+	const permissionUserReadWrite = 0o600
+	if err := os.WriteFile(filepath.Join(d.dir, name), data, permissionUserReadWrite); err != nil {
+		d.addErr(name, err)
+	}
+}
+
+func (d *diagnostics) writeYAML(name string, obj any) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		d.addErr(name, err)
+		return
+	}
+	d.write(name, data)
+}
+
+// collectDiagnostics stores the VM, the VMI with its status and conditions, the events of the VM, the VMI, the
+// DataVolumes with their PVCs and the virt-launcher pods, the virt-launcher pod logs including the serial console
+// log and the guest agent info of the VM in dir. It is called before a VM whose verification failed is deleted,
+// so failures can be debugged afterwards.
+func collectDiagnostics(ctx context.Context, client kvirtcli.KubevirtClient, namespace, name, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	d := &diagnostics{dir: dir}
+
+	objectNames := []string{name}
+	if vm, err := client.VirtualMachine(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		d.addErr("VM", err)
+	} else {
+		d.writeYAML("vm.yaml", vm)
+		objectNames = append(objectNames, dataVolumeNames(vm)...)
+	}
+
+	vmi, err := client.VirtualMachineInstance(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		d.addErr("VMI", err)
+	} else {
+		d.writeYAML("vmi.yaml", vmi)
+		objectNames = append(objectNames, d.collectPodLogs(ctx, client, vmi)...)
+		d.collectGuestAgentInfo(ctx, client, vmi)
+	}
+
+	if events, eventsErr := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{}); eventsErr != nil {
+		d.addErr("events", eventsErr)
+	} else {
+		d.write("events.txt", []byte(formatEvents(events.Items, objectNames)))
+	}
+
+	return errors.Join(d.errs...)
+}
+
+// dataVolumeNames returns the names of the DataVolumes of vm, which are also the names of their PVCs.
+func dataVolumeNames(vm *v1.VirtualMachine) []string {
+	var names []string
+	for i := range vm.Spec.DataVolumeTemplates {
+		names = append(names, vm.Spec.DataVolumeTemplates[i].Name)
+	}
+
+	return names
+}
+
+// collectPodLogs stores the logs of all containers of the virt-launcher pods of vmi and returns the pod names.
+func (d *diagnostics) collectPodLogs(ctx context.Context, client kvirtcli.KubevirtClient, vmi *v1.VirtualMachineInstance) []string {
+	pods, err := client.CoreV1().Pods(vmi.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: v1.CreatedByLabel + "=" + string(vmi.UID),
+	})
+	if err != nil {
+		d.addErr("virt-launcher pods", err)
+		return nil
+	}
+
+	var names []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		names = append(names, pod.Name)
+		for _, container := range pod.Spec.Containers {
+			logs, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &k8sv1.PodLogOptions{
+				Container: container.Name,
+			}).DoRaw(ctx)
+			if err != nil {
+				d.addErr(fmt.Sprintf("logs of %s/%s", pod.Name, container.Name), err)
+				continue
+			}
+			fileName := pod.Name + "_" + container.Name + ".log"
+			if container.Name == serialConsoleContainer {
+				fileName = "serial-console.log"
+			}
+			d.write(fileName, logs)
+		}
+	}
+
+	return names
+}
+
+// collectGuestAgentInfo stores the information the guest agent reports, if it is connected.
+func (d *diagnostics) collectGuestAgentInfo(ctx context.Context, client kvirtcli.KubevirtClient, vmi *v1.VirtualMachineInstance) {
+	if !slices.ContainsFunc(vmi.Status.Conditions, func(c v1.VirtualMachineInstanceCondition) bool {
+		return c.Type == v1.VirtualMachineInstanceAgentConnected && c.Status == k8sv1.ConditionTrue
+	}) {
+		return
+	}
+
+	vmiClient := client.VirtualMachineInstance(vmi.Namespace)
+	info := guestAgentInfo{}
+	if osInfo, err := vmiClient.GuestOsInfo(ctx, vmi.Name); err != nil {
+		d.addErr("guest OS info", err)
+	} else {
+		info.OSInfo = &osInfo
+	}
+	if users, err := vmiClient.UserList(ctx, vmi.Name); err != nil {
+		d.addErr("guest users", err)
+	} else {
+		info.Users = &users
+	}
+	if filesystems, err := vmiClient.FilesystemList(ctx, vmi.Name); err != nil {
+		d.addErr("guest filesystems", err)
+	} else {
+		info.Filesystems = &filesystems
+	}
+	d.writeYAML("guest-agent.yaml", info)
+}
+
+// formatEvents formats the events involving one of objectNames, one line per event in chronological order.
+func formatEvents(events []k8sv1.Event, objectNames []string) string {
+	events = slices.DeleteFunc(slices.Clone(events), func(e k8sv1.Event) bool {
+		return !slices.Contains(objectNames, e.InvolvedObject.Name)
+	})
+	slices.SortStableFunc(events, func(a, b k8sv1.Event) int {
+		return eventTime(&a).Compare(eventTime(&b).Time)
+	})
+
+	sb := strings.Builder{}
+	for i := range events {
+		e := &events[i]
+		fmt.Fprintf(&sb, "%s %s %s/%s %s: %s\n", eventTime(e).UTC().Format("2006-01-02T15:04:05Z"),
+			e.Type, e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Reason, e.Message)
+	}
+
+	return sb.String()
+}
+
+// eventTime returns when the event was last seen, events created with the events API only have EventTime set.
+func eventTime(e *k8sv1.Event) metav1.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp
+	}
+	return metav1.NewTime(e.EventTime.Time)
+}
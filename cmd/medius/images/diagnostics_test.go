@@ -0,0 +1,76 @@
+package images
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "kubevirt.io/api/core/v1"
+
+	"kubevirt.io/containerdisks/pkg/docs"
+)
+
+func event(kind, name, reason string, lastTimestamp, eventTime time.Time) k8sv1.Event {
+	return k8sv1.Event{
+		InvolvedObject: k8sv1.ObjectReference{Kind: kind, Name: name},
+		Type:           k8sv1.EventTypeNormal,
+		Reason:         reason,
+		Message:        reason + " " + name,
+		LastTimestamp:  metav1.NewTime(lastTimestamp),
+		EventTime:      metav1.NewMicroTime(eventTime),
+	}
+}
+
+var _ = Describe("Diagnostics", func() {
+	It("formatEvents should format the events of the VM objects in chronological order", func() {
+		start := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+		events := []k8sv1.Event{
+			event("VirtualMachineInstance", "fedora-abcde", "Started", start.Add(time.Minute), time.Time{}),
+			event("Pod", "virt-launcher-fedora-abcde-x2f4z", "Scheduled", time.Time{}, start.Add(time.Second)),
+			event("VirtualMachine", "ubuntu-fghij", "SuccessfulCreate", start, time.Time{}),
+			event("VirtualMachine", "fedora-abcde", "SuccessfulCreate", start, time.Time{}),
+		}
+		Expect(formatEvents(events, []string{"fedora-abcde", "virt-launcher-fedora-abcde-x2f4z"})).To(Equal(
+			"2026-10-19T10:00:00Z Normal VirtualMachine/fedora-abcde SuccessfulCreate: SuccessfulCreate fedora-abcde\n" +
+				"2026-10-19T10:00:01Z Normal Pod/virt-launcher-fedora-abcde-x2f4z Scheduled: Scheduled virt-launcher-fedora-abcde-x2f4z\n" +
+				"2026-10-19T10:01:00Z Normal VirtualMachineInstance/fedora-abcde Started: Started fedora-abcde\n",
+		))
+	})
+
+	It("should include the events of the DataVolumes and their PVCs", func() {
+		vm := docs.NewVM("fedora-abcde", "registry:5000/fedora:43")
+		Expect(withDataVolume(vm, "registry:5000/fedora:43", "amd64", resource.MustParse("20Gi"), "")).To(Succeed())
+		Expect(dataVolumeNames(vm)).To(Equal([]string{"fedora-abcde-containerdisk"}))
+
+		start := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+		events := []k8sv1.Event{
+			event("PersistentVolumeClaim", "fedora-abcde-containerdisk", "Provisioning", start.Add(time.Second), time.Time{}),
+			event("DataVolume", "fedora-abcde-containerdisk", "ImportInProgress", start, time.Time{}),
+			event("DataVolume", "ubuntu-fghij-containerdisk", "ImportInProgress", start, time.Time{}),
+		}
+		Expect(formatEvents(events, append([]string{vm.Name}, dataVolumeNames(vm)...))).To(Equal(
+			"2026-10-19T10:00:00Z Normal DataVolume/fedora-abcde-containerdisk ImportInProgress: ImportInProgress fedora-abcde-containerdisk\n" +
+				"2026-10-19T10:00:01Z Normal PersistentVolumeClaim/fedora-abcde-containerdisk Provisioning: Provisioning fedora-abcde-containerdisk\n",
+		))
+	})
+
+	It("should store objects as YAML and record errors", func() {
+		d := &diagnostics{dir: GinkgoT().TempDir()}
+		d.writeYAML("vmi.yaml", &v1.VirtualMachineInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "fedora-abcde"},
+			Status:     v1.VirtualMachineInstanceStatus{Phase: v1.Scheduling},
+		})
+		d.write(filepath.Join("missing", "events.txt"), nil)
+
+		data, err := os.ReadFile(filepath.Join(d.dir, "vmi.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("name: fedora-abcde"))
+		Expect(string(data)).To(ContainSubstring("phase: Scheduling"))
+		Expect(d.errs).To(ConsistOf(MatchError(ContainSubstring("failed to collect missing/events.txt"))))
+	})
+})
@@ -0,0 +1,260 @@
+package images
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+	"kubevirt.io/containerdisks/pkg/build"
+	"kubevirt.io/containerdisks/pkg/http"
+	"kubevirt.io/containerdisks/pkg/repository"
+	"kubevirt.io/containerdisks/testutil"
+)
+
+// e2eHarness runs medius commands against an in-memory registry. All upstream requests are sent to a server
+// serving the fixtures.
+type e2eHarness struct {
+	// registry is the in-memory registry, e.g. "127.0.0.1:12345".
+	registry string
+	upstream *httptest.Server
+	// downloads counts the disks downloaded from upstream.
+	downloads   atomic.Int32
+	resultsFile string
+	repo        repository.Repository
+}
+
+func newE2EHarness() *e2eHarness {
+	h := &e2eHarness{
+		resultsFile: filepath.Join(GinkgoT().TempDir(), "results.json"),
+		repo:        &repository.RepositoryImpl{},
+	}
+
+	registryServer := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	DeferCleanup(registryServer.Close)
+	h.registry = strings.TrimPrefix(registryServer.URL, "http://")
+
+	fixtures := testutil.NewUpstreamHandler(fixturesDir)
+	h.upstream = httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		if r.Method == stdhttp.MethodGet && strings.HasSuffix(r.URL.Path, ".qcow2") {
+			h.downloads.Add(1)
+		}
+		fixtures.ServeHTTP(w, r)
+	}))
+	DeferCleanup(h.upstream.Close)
+
+	// Commands fail with logrus.Fatal, which must not exit the test binary
+	exitFunc := logrus.StandardLogger().ExitFunc
+	logrus.StandardLogger().ExitFunc = func(code int) {
+		panic(fmt.Sprintf("medius exited with code %d", code))
+	}
+	DeferCleanup(func() {
+		logrus.StandardLogger().ExitFunc = exitFunc
+	})
+
+	return h
+}
+
+// run executes a command with the options medius sets up for all images commands.
+func (h *e2eHarness) run(newCommand func(*common.Options) *cobra.Command, args ...string) {
+	options := &common.Options{
+		AllowInsecureRegistry: true,
+		Focus:                 "debian:11",
+		ImagesOptions: common.ImagesOptions{
+			ResultsFile: h.resultsFile,
+			Workers:     1,
+		},
+		Upstream: http.HTTPGetter{Client: testutil.NewUpstreamClient(h.upstream)},
+	}
+	cmd := newCommand(options)
+	cmd.SetArgs(args)
+	Expect(cmd.ExecuteContext(context.Background())).To(Succeed())
+}
+
+func (h *e2eHarness) push(args ...string) {
+	h.run(NewPublishImagesCommand, append([]string{
+		"--source-registry=" + h.registry + "/containerdisks",
+		"--target-registry=" + h.registry + "/containerdisks",
+	}, args...)...)
+}
+
+func (h *e2eHarness) promote(args ...string) {
+	h.run(NewPromoteImagesCommand, append([]string{
+		"--source-registry=" + h.registry + "/containerdisks",
+		"--target-registry=" + h.registry + "/promoted",
+	}, args...)...)
+}
+
+// verify hands the results of push over to promote like verify does after verifying the given architectures.
+// Failed architectures set the error of the containerdisk.
+func (h *e2eHarness) verify(description string, architectures map[string]api.ArchitectureResult) {
+	results, err := readResultsFile(h.resultsFile)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(results).To(HaveKey(description))
+
+	result := results[description]
+	result.Stage = StageVerify
+	result.Architectures = architectures
+	var errs []error
+	for _, arch := range slices.Sorted(maps.Keys(architectures)) {
+		if !architectures[arch].Verified {
+			errs = append(errs, fmt.Errorf("%s: %s", arch, architectures[arch].Err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		result.Err = err.Error()
+	}
+	results[description] = result
+	Expect(writeResultsFile(h.resultsFile, results)).To(Succeed())
+}
+
+func (h *e2eHarness) results() map[string]api.ArtifactResult {
+	results, err := readResultsFile(h.resultsFile)
+	Expect(err).ToNot(HaveOccurred())
+	return results
+}
+
+func (h *e2eHarness) tags(repo string) []string {
+	tags, err := h.repo.ListTags(context.Background(), path.Join(h.registry, repo), true)
+	Expect(err).ToNot(HaveOccurred())
+	return tags
+}
+
+// platforms returns the architectures of the image index and checks the checksum label of every image.
+func (h *e2eHarness) platforms(imgRef string) []string {
+	idx, err := h.repo.ImageIndex(context.Background(), path.Join(h.registry, imgRef), true)
+	Expect(err).ToNot(HaveOccurred())
+	manifest, err := idx.IndexManifest()
+	Expect(err).ToNot(HaveOccurred())
+
+	var archs []string
+	for _, descriptor := range manifest.Manifests {
+		img, err := idx.Image(descriptor.Digest)
+		Expect(err).ToNot(HaveOccurred())
+		config, err := img.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Config.Labels).To(HaveKeyWithValue(build.LabelShaSum, fixtureChecksum(descriptor.Platform)))
+		archs = append(archs, descriptor.Platform.Architecture)
+	}
+
+	return archs
+}
+
+// fixtureChecksum returns the checksum of the fixture disk of a platform.
+func fixtureChecksum(platform *v1.Platform) string {
+	content := "fixture disk\n"
+	if platform.Architecture == "arm64" {
+		content = "fixture disk arm64\n"
+	}
+	checksum := sha512.Sum512([]byte(content))
+	return hex.EncodeToString(checksum[:])
+}
+
+var _ = Describe("End to end", func() {
+	var h *e2eHarness
+
+	BeforeEach(func() {
+		h = newE2EHarness()
+	})
+
+	It("push should publish multi-arch containerdisks with all tags", func() {
+		h.push()
+
+		Expect(h.downloads.Load()).To(BeEquivalentTo(2))
+		results := h.results()
+		Expect(results).To(HaveKey("debian:11"))
+		Expect(results["debian:11"].Stage).To(Equal(StagePush))
+		Expect(results["debian:11"].Err).To(BeEmpty())
+		Expect(results["debian:11"].Tags).To(HaveExactElements(
+			MatchRegexp(`^debian:11-\d{10}$`), "debian:11-20251019-0000", "debian:11",
+		))
+
+		Expect(h.tags("containerdisks/debian")).To(ConsistOf(
+			MatchRegexp(`^11-\d{10}$`), "11-20251019-0000", "11",
+		))
+		Expect(h.platforms("containerdisks/debian:11")).To(ConsistOf("amd64", "arm64"))
+	})
+
+	It("push should skip unchanged containerdisks unless forced", func() {
+		h.push()
+		Expect(h.downloads.Load()).To(BeEquivalentTo(2))
+
+		h.push()
+		Expect(h.downloads.Load()).To(BeEquivalentTo(2))
+		Expect(h.results()).ToNot(HaveKey("debian:11"))
+
+		h.push("--force")
+		Expect(h.downloads.Load()).To(BeEquivalentTo(4))
+		Expect(h.results()).To(HaveKey("debian:11"))
+		Expect(h.platforms("containerdisks/debian:11")).To(ConsistOf("amd64", "arm64"))
+	})
+
+	It("promote should promote the verified architectures of the pushed containerdisks", func() {
+		h.push()
+		h.verify("debian:11", map[string]api.ArchitectureResult{"amd64": {Verified: true}})
+		pushed := h.results()["debian:11"]
+
+		h.promote("--arch-policy=verified")
+
+		result := h.results()["debian:11"]
+		Expect(result.Stage).To(Equal(StagePromote))
+		Expect(result.Err).To(BeEmpty())
+		Expect(result.Digest).ToNot(BeEmpty())
+		Expect(result.Tags).To(Equal(pushed.Tags))
+		Expect(result.Targets).To(HaveKeyWithValue(h.registry+"/promoted", api.TargetResult{}))
+
+		var promotedTags []string
+		for _, tag := range pushed.Tags {
+			promotedTags = append(promotedTags, strings.TrimPrefix(tag, "debian:"))
+		}
+		Expect(h.tags("promoted/debian")).To(ConsistOf(promotedTags))
+		Expect(h.platforms("promoted/debian:11")).To(ConsistOf("amd64"))
+		Expect(h.platforms("containerdisks/debian:11")).To(ConsistOf("amd64", "arm64"))
+	})
+
+	It("promote should only promote the verified architectures of containerdisks which failed on others", func() {
+		h.push()
+		h.verify("debian:11", map[string]api.ArchitectureResult{
+			"amd64": {Verified: true},
+			"arm64": {Verified: false, Err: "VM not ready"},
+		})
+
+		h.promote("--arch-policy=verified")
+
+		result := h.results()["debian:11"]
+		Expect(result.Stage).To(Equal(StagePromote))
+		Expect(result.Err).To(BeEmpty())
+		Expect(result.Architectures).To(HaveKeyWithValue("arm64", api.ArchitectureResult{Err: "VM not ready"}))
+		Expect(h.platforms("promoted/debian:11")).To(ConsistOf("amd64"))
+	})
+
+	It("promote should promote all architectures by default", func() {
+		h.push()
+		h.verify("debian:11", map[string]api.ArchitectureResult{"amd64": {Verified: true}})
+
+		h.promote()
+
+		Expect(h.results()["debian:11"].Err).To(BeEmpty())
+		Expect(h.platforms("promoted/debian:11")).To(ConsistOf("amd64", "arm64"))
+	})
+})
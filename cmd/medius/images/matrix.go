@@ -0,0 +1,141 @@
+package images
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/utils/ptr"
+	v1 "kubevirt.io/api/core/v1"
+
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+	pkgcommon "kubevirt.io/containerdisks/pkg/common"
+	"kubevirt.io/containerdisks/pkg/docs"
+)
+
+const (
+	// ConfigurationDefault keeps the firmware or disk bus of the VM of the artifact.
+	ConfigurationDefault = "default"
+
+	FirmwareBIOS       = "bios"
+	FirmwareEFI        = "efi"
+	FirmwareSecureBoot = "secureboot"
+)
+
+var (
+	firmwares = []string{ConfigurationDefault, FirmwareBIOS, FirmwareEFI, FirmwareSecureBoot}
+	diskBuses = []string{ConfigurationDefault, string(v1.DiskBusVirtio), string(v1.DiskBusSATA)}
+)
+
+// vmConfiguration is a configuration of the VM of an artifact, which is verified in addition to the VM itself.
+type vmConfiguration struct {
+	Firmware string
+	DiskBus  string
+	// Instancetype applies the default instancetype and preference of the containerdisk.
+	Instancetype bool
+}
+
+var defaultVMConfiguration = vmConfiguration{Firmware: ConfigurationDefault, DiskBus: ConfigurationDefault}
+
+// String returns the name of the configuration in results and docs, e.g. "firmware=efi,bus=sata".
+func (c vmConfiguration) String() string {
+	var parts []string
+	if c.Firmware != ConfigurationDefault {
+		parts = append(parts, "firmware="+c.Firmware)
+	}
+	if c.DiskBus != ConfigurationDefault {
+		parts = append(parts, "bus="+c.DiskBus)
+	}
+	if c.Instancetype {
+		parts = append(parts, "instancetype")
+	}
+	if len(parts) == 0 {
+		return api.DefaultConfiguration
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// vmConfigurations returns all combinations of the configured firmwares, disk buses and instancetype usage.
+// The default configuration is always first.
+func vmConfigurations(o *common.VerifyImageOptions) ([]vmConfiguration, error) {
+	for _, firmware := range o.Firmwares {
+		if !slices.Contains(firmwares, firmware) {
+			return nil, fmt.Errorf("unknown firmware %q, supported are %v", firmware, firmwares)
+		}
+	}
+	for _, bus := range o.DiskBuses {
+		if !slices.Contains(diskBuses, bus) {
+			return nil, fmt.Errorf("unknown disk bus %q, supported are %v", bus, diskBuses)
+		}
+	}
+
+	instancetypes := []bool{false}
+	if o.Instancetype {
+		instancetypes = append(instancetypes, true)
+	}
+
+	configurations := []vmConfiguration{defaultVMConfiguration}
+	for _, firmware := range o.Firmwares {
+		for _, bus := range o.DiskBuses {
+			for _, instancetype := range instancetypes {
+				c := vmConfiguration{Firmware: firmware, DiskBus: bus, Instancetype: instancetype}
+				if !slices.Contains(configurations, c) {
+					configurations = append(configurations, c)
+				}
+			}
+		}
+	}
+
+	return configurations, nil
+}
+
+// artifactVMConfigurations returns the configurations which can be applied to the VM of the artifact.
+func artifactVMConfigurations(configurations []vmConfiguration, metadata *api.Metadata) []vmConfiguration {
+	return slices.DeleteFunc(slices.Clone(configurations), func(c vmConfiguration) bool {
+		return c.Instancetype && metadata.EnvVariables[pkgcommon.DefaultInstancetypeEnv] == ""
+	})
+}
+
+// apply changes vm according to the configuration.
+func (c vmConfiguration) apply(vm *v1.VirtualMachine, metadata *api.Metadata) {
+	spec := &vm.Spec.Template.Spec
+
+	switch c.Firmware {
+	case FirmwareBIOS:
+		setBootloader(spec, &v1.Bootloader{BIOS: &v1.BIOS{}})
+	case FirmwareEFI:
+		setBootloader(spec, &v1.Bootloader{EFI: &v1.EFI{SecureBoot: ptr.To(false)}})
+	case FirmwareSecureBoot:
+		docs.WithSecureBoot()(vm)
+	}
+
+	if c.DiskBus != ConfigurationDefault {
+		for i := range spec.Domain.Devices.Disks {
+			if disk := spec.Domain.Devices.Disks[i].Disk; disk != nil {
+				disk.Bus = v1.DiskBus(c.DiskBus)
+			}
+		}
+	}
+
+	if c.Instancetype {
+		vm.Spec.Instancetype = &v1.InstancetypeMatcher{Name: metadata.EnvVariables[pkgcommon.DefaultInstancetypeEnv]}
+		if preference := metadata.EnvVariables[pkgcommon.DefaultPreferenceEnv]; preference != "" {
+			vm.Spec.Preference = &v1.PreferenceMatcher{Name: preference}
+		}
+		// The instancetype provides the resources, they must not be set in the VM as well
+		spec.Domain.Resources = v1.ResourceRequirements{}
+	}
+}
+
+// setBootloader sets the bootloader and disables SMM, which is only needed for SecureBoot.
+func setBootloader(spec *v1.VirtualMachineInstanceSpec, bootloader *v1.Bootloader) {
+	if spec.Domain.Firmware == nil {
+		spec.Domain.Firmware = &v1.Firmware{}
+	}
+	spec.Domain.Firmware.Bootloader = bootloader
+	if spec.Domain.Features != nil {
+		spec.Domain.Features.SMM = nil
+	}
+}
@@ -0,0 +1,105 @@
+package images
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+	v1 "kubevirt.io/api/core/v1"
+
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+	pkgcommon "kubevirt.io/containerdisks/pkg/common"
+	"kubevirt.io/containerdisks/pkg/docs"
+)
+
+func configurationNames(configurations []vmConfiguration) []string {
+	var names []string
+	for _, c := range configurations {
+		names = append(names, c.String())
+	}
+	return names
+}
+
+var _ = Describe("Matrix", func() {
+	metadata := &api.Metadata{
+		Name:    "fedora",
+		Version: "43",
+		EnvVariables: map[string]string{
+			pkgcommon.DefaultInstancetypeEnv: "u1.medium",
+			pkgcommon.DefaultPreferenceEnv:   "fedora",
+		},
+	}
+
+	secureBootVM := func() *v1.VirtualMachine {
+		return docs.NewVM("fedora-abcde", "registry:5000/fedora:43", docs.WithCloudInitNoCloud("#cloud-config"), docs.WithSecureBoot())
+	}
+
+	It("vmConfigurations should return the default configuration only by default", func() {
+		configurations, err := vmConfigurations(&common.VerifyImageOptions{
+			Firmwares: []string{ConfigurationDefault},
+			DiskBuses: []string{ConfigurationDefault},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configurations).To(Equal([]vmConfiguration{defaultVMConfiguration}))
+	})
+
+	It("vmConfigurations should return all combinations with the default configuration first", func() {
+		configurations, err := vmConfigurations(&common.VerifyImageOptions{
+			Firmwares:    []string{FirmwareBIOS, FirmwareEFI},
+			DiskBuses:    []string{ConfigurationDefault, "sata"},
+			Instancetype: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configurationNames(configurations)).To(Equal([]string{
+			"default",
+			"firmware=bios", "firmware=bios,instancetype", "firmware=bios,bus=sata", "firmware=bios,bus=sata,instancetype",
+			"firmware=efi", "firmware=efi,instancetype", "firmware=efi,bus=sata", "firmware=efi,bus=sata,instancetype",
+		}))
+	})
+
+	It("vmConfigurations should reject unknown values", func() {
+		_, err := vmConfigurations(&common.VerifyImageOptions{Firmwares: []string{"uefi"}})
+		Expect(err).To(MatchError(`unknown firmware "uefi", supported are [default bios efi secureboot]`))
+		_, err = vmConfigurations(&common.VerifyImageOptions{DiskBuses: []string{"scsi"}})
+		Expect(err).To(MatchError(`unknown disk bus "scsi", supported are [default virtio sata]`))
+	})
+
+	It("artifactVMConfigurations should skip instancetype configurations without a default instancetype", func() {
+		configurations := []vmConfiguration{defaultVMConfiguration, {Firmware: FirmwareBIOS, DiskBus: ConfigurationDefault, Instancetype: true}}
+		Expect(artifactVMConfigurations(configurations, metadata)).To(HaveLen(2))
+		Expect(artifactVMConfigurations(configurations, &api.Metadata{Name: "cirros"})).To(Equal([]vmConfiguration{defaultVMConfiguration}))
+	})
+
+	It("apply should keep the default configuration", func() {
+		vm := secureBootVM()
+		defaultVMConfiguration.apply(vm, metadata)
+		Expect(vm).To(Equal(secureBootVM()))
+	})
+
+	It("apply should switch to BIOS and the SATA bus", func() {
+		vm := secureBootVM()
+		vmConfiguration{Firmware: FirmwareBIOS, DiskBus: "sata"}.apply(vm, metadata)
+
+		domain := vm.Spec.Template.Spec.Domain
+		Expect(domain.Firmware.Bootloader).To(Equal(&v1.Bootloader{BIOS: &v1.BIOS{}}))
+		Expect(domain.Features.SMM).To(BeNil())
+		Expect(domain.Devices.Disks).To(HaveLen(2))
+		for _, disk := range domain.Devices.Disks {
+			Expect(disk.Disk.Bus).To(Equal(v1.DiskBusSATA))
+		}
+	})
+
+	It("apply should switch to EFI without SecureBoot", func() {
+		vm := secureBootVM()
+		vmConfiguration{Firmware: FirmwareEFI, DiskBus: ConfigurationDefault}.apply(vm, metadata)
+		Expect(vm.Spec.Template.Spec.Domain.Firmware.Bootloader.EFI.SecureBoot).To(Equal(ptr.To(false)))
+	})
+
+	It("apply should use the default instancetype and preference", func() {
+		vm := secureBootVM()
+		vmConfiguration{Firmware: ConfigurationDefault, DiskBus: ConfigurationDefault, Instancetype: true}.apply(vm, metadata)
+		Expect(vm.Spec.Instancetype).To(Equal(&v1.InstancetypeMatcher{Name: "u1.medium"}))
+		Expect(vm.Spec.Preference).To(Equal(&v1.PreferenceMatcher{Name: "fedora"}))
+		Expect(vm.Spec.Template.Spec.Domain.Resources.Requests).To(BeEmpty())
+	})
+})
@@ -0,0 +1,246 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	v1 "kubevirt.io/api/core/v1"
+	kvirtcli "kubevirt.io/client-go/kubecli"
+
+	"kubevirt.io/containerdisks/pkg/api"
+	"kubevirt.io/containerdisks/pkg/tests"
+)
+
+// bootMetric describes a boot metric and when a change of it is a regression.
+type bootMetric struct {
+	name   string
+	value  func(m *api.BootMetrics) float64
+	format func(value float64) string
+	// minIncrease is the increase which is always considered noise, regardless of the threshold.
+	minIncrease float64
+}
+
+const (
+	mebibyte = 1024 * 1024
+	percent  = 100
+	// defaultRegressionThreshold is the default increase of a boot metric in percent which is a regression.
+	defaultRegressionThreshold = 25
+)
+
+func formatSeconds(value float64) string {
+	return fmt.Sprintf("%.1fs", value)
+}
+
+func formatMebibytes(value float64) string {
+	return fmt.Sprintf("%.0fMi", value/mebibyte)
+}
+
+var bootMetrics = []bootMetric{
+	{
+		name:        "ready",
+		value:       func(m *api.BootMetrics) float64 { return m.Ready },
+		format:      formatSeconds,
+		minIncrease: 5,
+	},
+	{
+		name:        "guest agent connected",
+		value:       func(m *api.BootMetrics) float64 { return m.GuestAgentConnected },
+		format:      formatSeconds,
+		minIncrease: 5,
+	},
+	{
+		name:        "SSH reachable",
+		value:       func(m *api.BootMetrics) float64 { return m.SSHReachable },
+		format:      formatSeconds,
+		minIncrease: 5,
+	},
+	{
+		name:        "memory used",
+		value:       func(m *api.BootMetrics) float64 { return float64(m.MemoryUsed) },
+		format:      formatMebibytes,
+		minIncrease: 32 * mebibyte,
+	},
+}
+
+// bootMetricComparison compares a boot metric of a containerdisk on an architecture with the previous run.
+type bootMetricComparison struct {
+	Artifact string
+	Arch     string
+	Metric   *bootMetric
+	Previous float64
+	Current  float64
+	// Regressed indicates that the metric increased by more than the threshold.
+	Regressed bool
+}
+
+// Change returns the relative change of the metric in percent.
+func (c *bootMetricComparison) Change() float64 {
+	return (c.Current - c.Previous) / c.Previous * percent
+}
+
+func (c *bootMetricComparison) String() string {
+	return fmt.Sprintf("%s %s -> %s (%+.0f%%)", c.Metric.name, c.Metric.format(c.Previous), c.Metric.format(c.Current), c.Change())
+}
+
+// compareBootMetrics compares the metrics which were measured in both runs. A metric regressed if it increased by
+// more than threshold percent and more than the noise of the metric.
+func compareBootMetrics(artifact, arch string, previous, current *api.BootMetrics, threshold int) []bootMetricComparison {
+	if previous == nil || current == nil {
+		return nil
+	}
+
+	var comparisons []bootMetricComparison
+	for i := range bootMetrics {
+		metric := &bootMetrics[i]
+		previousValue, currentValue := metric.value(previous), metric.value(current)
+		if previousValue == 0 || currentValue == 0 {
+			continue
+		}
+		increase := currentValue - previousValue
+		comparisons = append(comparisons, bootMetricComparison{
+			Artifact: artifact,
+			Arch:     arch,
+			Metric:   metric,
+			Previous: previousValue,
+			Current:  currentValue,
+			Regressed: increase > metric.minIncrease &&
+				increase > previousValue*float64(threshold)/percent,
+		})
+	}
+
+	return comparisons
+}
+
+// compareResults compares the boot metrics of all containerdisks and architectures verified in both runs.
+func compareResults(previous, current map[string]api.ArtifactResult, threshold int) []bootMetricComparison {
+	var comparisons []bootMetricComparison
+	for _, artifact := range slices.Sorted(maps.Keys(current)) {
+		previousArchs := previous[artifact].Architectures
+		currentArchs := current[artifact].Architectures
+		for _, arch := range slices.Sorted(maps.Keys(currentArchs)) {
+			previousArch, ok := previousArchs[arch]
+			if !ok {
+				continue
+			}
+			comparisons = append(comparisons,
+				compareBootMetrics(artifact, arch, previousArch.BootMetrics, currentArchs[arch].BootMetrics, threshold)...)
+		}
+	}
+
+	return comparisons
+}
+
+// failBootRegressions marks all architectures whose boot metrics regressed compared to the previous result as not
+// verified and returns an error describing the regressions.
+func failBootRegressions(artifact string, previous api.ArtifactResult, architectures map[string]api.ArchitectureResult,
+	threshold int,
+) error {
+	var errs []error
+	for _, arch := range slices.Sorted(maps.Keys(architectures)) {
+		result := architectures[arch]
+		previousArch, ok := previous.Architectures[arch]
+		if !ok || !result.Verified {
+			continue
+		}
+
+		var regressions []string
+		for _, c := range compareBootMetrics(artifact, arch, previousArch.BootMetrics, result.BootMetrics, threshold) {
+			if c.Regressed {
+				regressions = append(regressions, c.String())
+			}
+		}
+		if len(regressions) == 0 {
+			continue
+		}
+
+		err := fmt.Errorf("boot metrics regressed by more than %d%%: %s", threshold, strings.Join(regressions, ", "))
+		result.Verified = false
+		result.Err = err.Error()
+		architectures[arch] = result
+		errs = append(errs, fmt.Errorf("%s: %w", arch, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// secondsSince returns the seconds since t, rounded to a tenth of a second.
+func secondsSince(t time.Time) float64 {
+	const precision = 10
+	return math.Round(time.Since(t).Seconds()*precision) / precision
+}
+
+// measureGuest starts measuring the time until the guest agent of vm connected and the memory used by the guest
+// right after, while the tests run. Both are measured with the clock of created. The returned function stops
+// measuring and adds the results to metrics. Failures are only logged, as not all guests provide them.
+func measureGuest(ctx context.Context, client kvirtcli.KubevirtClient, vm *v1.VirtualMachine, params *api.ArtifactTestParams,
+	created time.Time, log *logrus.Entry,
+) (stop func(metrics *api.BootMetrics)) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	var agentConnected float64
+	var memoryUsed int64
+	go func() {
+		defer close(done)
+		vmi, err := waitAgentConnected(ctx, client.VirtualMachineInstance(vm.Namespace), vm.Name)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.WithError(err).Warn("Failed to measure when the guest agent connected")
+			}
+			return
+		}
+		agentConnected = secondsSince(created)
+
+		if params.Username == "" {
+			return
+		}
+		if err = tests.WaitSSHReachable(ctx, vmi, params); err == nil {
+			memoryUsed, err = tests.MemoryUsed(vmi, params)
+		}
+		if err != nil && ctx.Err() == nil {
+			log.WithError(err).Warn("Failed to measure the memory used by the guest")
+		}
+	}()
+
+	return func(metrics *api.BootMetrics) {
+		cancel()
+		<-done
+		metrics.GuestAgentConnected = agentConnected
+		metrics.MemoryUsed = memoryUsed
+	}
+}
+
+// waitAgentConnected polls the VMI until its guest agent connected and returns it.
+func waitAgentConnected(ctx context.Context, client kvirtcli.VirtualMachineInstanceInterface, name string,
+) (vmi *v1.VirtualMachineInstance, err error) {
+	const pollInterval = time.Second
+	err = wait.PollUntilContextCancel(ctx, pollInterval, true, func(ctx context.Context) (bool, error) {
+		vmi, err = client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return agentConnected(vmi), nil
+	})
+
+	return vmi, err
+}
+
+// agentConnected returns whether the guest agent of vmi is connected.
+func agentConnected(vmi *v1.VirtualMachineInstance) bool {
+	for _, c := range vmi.Status.Conditions {
+		if c.Type == v1.VirtualMachineInstanceAgentConnected && c.Status == k8sv1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,95 @@
+package images
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+	v1 "kubevirt.io/api/core/v1"
+
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+func regressedMetrics(comparisons []bootMetricComparison) []string {
+	var names []string
+	for i := range comparisons {
+		if comparisons[i].Regressed {
+			names = append(names, comparisons[i].Metric.name)
+		}
+	}
+	return names
+}
+
+var _ = Describe("Metrics", func() {
+	previous := &api.BootMetrics{Ready: 30, GuestAgentConnected: 40, SSHReachable: 3, MemoryUsed: 200 * mebibyte}
+
+	DescribeTable("compareBootMetrics should detect regressions past the threshold",
+		func(current *api.BootMetrics, regressed []string) {
+			Expect(regressedMetrics(compareBootMetrics("fedora:43", "amd64", previous, current, 25))).To(Equal(regressed))
+		},
+		Entry("unchanged", previous, nil),
+		Entry("slower boot",
+			&api.BootMetrics{Ready: 70, GuestAgentConnected: 80, SSHReachable: 3, MemoryUsed: 200 * mebibyte},
+			[]string{"ready", "guest agent connected"}),
+		Entry("increase below the threshold", &api.BootMetrics{Ready: 37, MemoryUsed: 240 * mebibyte}, nil),
+		Entry("increase below the noise", &api.BootMetrics{SSHReachable: 6}, nil),
+		Entry("more memory used", &api.BootMetrics{MemoryUsed: 300 * mebibyte}, []string{"memory used"}),
+	)
+
+	It("compareBootMetrics should skip metrics which were not measured in both runs", func() {
+		Expect(compareBootMetrics("fedora:43", "amd64", previous, &api.BootMetrics{Ready: 31}, 25)).To(HaveLen(1))
+		Expect(compareBootMetrics("fedora:43", "amd64", nil, previous, 25)).To(BeEmpty())
+	})
+
+	It("failBootRegressions should fail architectures whose boot metrics regressed", func() {
+		architectures := map[string]api.ArchitectureResult{
+			"amd64": {Verified: true, BootMetrics: &api.BootMetrics{Ready: 70}},
+			"arm64": {Verified: true, BootMetrics: &api.BootMetrics{Ready: 31}},
+			"s390x": {Verified: true, BootMetrics: &api.BootMetrics{Ready: 70}},
+		}
+		previousResult := api.ArtifactResult{
+			Architectures: map[string]api.ArchitectureResult{
+				"amd64": {Verified: true, BootMetrics: previous},
+				"arm64": {Verified: true, BootMetrics: previous},
+			},
+		}
+
+		err := failBootRegressions("fedora:43", previousResult, architectures, 25)
+		Expect(err).To(MatchError("amd64: boot metrics regressed by more than 25%: ready 30.0s -> 70.0s (+133%)"))
+		Expect(architectures["amd64"].Verified).To(BeFalse())
+		Expect(architectures["amd64"].Err).To(ContainSubstring("boot metrics regressed"))
+		Expect(architectures["arm64"].Verified).To(BeTrue())
+		Expect(architectures["s390x"].Verified).To(BeTrue())
+	})
+
+	It("writeReport should list the comparisons of all containerdisks", func() {
+		results := func(ready float64) map[string]api.ArtifactResult {
+			return map[string]api.ArtifactResult{
+				"fedora:43": {Architectures: map[string]api.ArchitectureResult{
+					"arm64": {BootMetrics: &api.BootMetrics{Ready: ready}},
+					"amd64": {BootMetrics: &api.BootMetrics{Ready: 30}},
+				}},
+			}
+		}
+
+		out := &bytes.Buffer{}
+		Expect(writeReport(out, compareResults(results(30), results(70), 25))).To(Succeed())
+		Expect(out.String()).To(Equal(
+			"CONTAINERDISK  ARCH   METRIC  PREVIOUS  CURRENT  CHANGE  \n" +
+				"fedora:43      amd64  ready   30.0s     30.0s    +0%     \n" +
+				"fedora:43      arm64  ready   30.0s     70.0s    +133%   REGRESSION\n",
+		))
+	})
+
+	It("agentConnected should return whether the guest agent connected", func() {
+		vmi := &v1.VirtualMachineInstance{Status: v1.VirtualMachineInstanceStatus{
+			Conditions: []v1.VirtualMachineInstanceCondition{
+				{Type: v1.VirtualMachineInstanceReady, Status: k8sv1.ConditionTrue},
+				{Type: v1.VirtualMachineInstanceAgentConnected, Status: k8sv1.ConditionTrue},
+			},
+		}}
+		Expect(agentConnected(vmi)).To(BeTrue())
+		Expect(agentConnected(&v1.VirtualMachineInstance{})).To(BeFalse())
+	})
+})
@@ -0,0 +1,87 @@
+package images
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+// buildPlan contains the inspected details of all artifacts of an entry. Artifacts are inspected
+// exactly once, so the checksums compared, the URLs downloaded and the tags created all refer to the
+// same upstream build, even if upstream publishes a new one while the entry is processed.
+type buildPlan struct {
+	useForLatest bool
+	artifacts    []plannedArtifact
+}
+
+type plannedArtifact struct {
+	metadata *api.Metadata
+	details  api.ArtifactDetails
+}
+
+func newBuildPlan(entry *common.Entry) (*buildPlan, error) {
+	if len(entry.Artifacts) == 0 {
+		return nil, errors.New("entry has no artifacts")
+	}
+
+	plan := &buildPlan{useForLatest: entry.UseForLatest}
+	for _, artifact := range entry.Artifacts {
+		metadata := artifact.Metadata()
+		details, err := artifact.Inspect()
+		if err != nil {
+			return nil, fmt.Errorf("error introspecting artifact %q: %v", metadata.Describe(), err)
+		}
+		plan.artifacts = append(plan.artifacts, plannedArtifact{
+			metadata: metadata,
+			// Copy the details, so the plan is not affected by changes to the returned struct
+			details: *details,
+		})
+	}
+
+	return plan, nil
+}
+
+// Artifacts returns copies of the planned artifact details.
+func (p *buildPlan) Artifacts() []api.ArtifactDetails {
+	details := make([]api.ArtifactDetails, 0, len(p.artifacts))
+	for _, artifact := range p.artifacts {
+		d := artifact.details
+		d.AdditionalUniqueTags = slices.Clone(artifact.details.AdditionalUniqueTags)
+		d.MirrorURLs = slices.Clone(artifact.details.MirrorURLs)
+		details = append(details, d)
+	}
+
+	return details
+}
+
+func (p *buildPlan) Metadata(i int) *api.Metadata {
+	return p.artifacts[i].metadata
+}
+
+// Tags returns all image names the entry is pushed with in registry. The tags are derived from the
+// first artifact, the least specific tag is last except for "latest".
+func (p *buildPlan) Tags(timestamp time.Time, registry string) []string {
+	metadata := p.artifacts[0].metadata
+	imageName := path.Join(registry, metadata.Describe())
+
+	names := []string{fmt.Sprintf("%s-%s", imageName, timestamp.Format(tagTimestampFormat))}
+	for _, tag := range p.artifacts[0].details.AdditionalUniqueTags {
+		if tag == "" {
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s:%s", path.Join(registry, metadata.Name), tag))
+	}
+	// the least specific tag is last
+	names = append(names, imageName)
+
+	if p.useForLatest {
+		names = append(names, fmt.Sprintf("%s:%s", path.Join(registry, metadata.Name), "latest"))
+	}
+
+	return names
+}
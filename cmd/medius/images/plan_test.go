@@ -0,0 +1,79 @@
+package images
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/containerdisks/artifacts/generic"
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+// changingArtifact simulates an upstream which publishes a new build on every Inspect call.
+type changingArtifact struct {
+	api.Artifact
+	inspections int
+}
+
+func (c *changingArtifact) Inspect() (*api.ArtifactDetails, error) {
+	c.inspections++
+	return &api.ArtifactDetails{
+		Checksum:             fmt.Sprintf("checksum-%d", c.inspections),
+		AdditionalUniqueTags: []string{fmt.Sprintf("43-1.%d", c.inspections)},
+		MirrorURLs:           []string{fmt.Sprintf("https://mirror.example.com/%d.img", c.inspections)},
+	}, nil
+}
+
+var _ = Describe("Plan", func() {
+	var (
+		artifact *changingArtifact
+		entry    *common.Entry
+	)
+
+	BeforeEach(func() {
+		artifact = &changingArtifact{
+			Artifact: generic.New(&api.ArtifactDetails{}, &api.Metadata{Name: "fedora", Version: "43"}),
+		}
+		entry = &common.Entry{
+			Artifacts:    []api.Artifact{artifact},
+			UseForLatest: true,
+		}
+	})
+
+	It("should inspect every artifact once and not change afterwards", func() {
+		plan, err := newBuildPlan(entry)
+		Expect(err).ToNot(HaveOccurred())
+
+		details := plan.Artifacts()
+		details[0].Checksum = "modified"
+		details[0].AdditionalUniqueTags[0] = "modified"
+		details[0].MirrorURLs[0] = "modified"
+
+		Expect(plan.Artifacts()[0].Checksum).To(Equal("checksum-1"))
+		Expect(plan.Artifacts()[0].AdditionalUniqueTags).To(Equal([]string{"43-1.1"}))
+		Expect(plan.Artifacts()[0].MirrorURLs).To(Equal([]string{"https://mirror.example.com/1.img"}))
+		Expect(artifact.inspections).To(Equal(1))
+	})
+
+	It("should derive the tags from the plan", func() {
+		plan, err := newBuildPlan(entry)
+		Expect(err).ToNot(HaveOccurred())
+
+		timestamp := time.Date(2025, 10, 19, 12, 0, 0, 0, time.UTC)
+		Expect(plan.Tags(timestamp, "quay.io/containerdisks")).To(Equal([]string{
+			"quay.io/containerdisks/fedora:43-2510191200",
+			"quay.io/containerdisks/fedora:43-1.1",
+			"quay.io/containerdisks/fedora:43",
+			"quay.io/containerdisks/fedora:latest",
+		}))
+		Expect(artifact.inspections).To(Equal(1))
+	})
+
+	It("should fail for entries without artifacts", func() {
+		_, err := newBuildPlan(&common.Entry{})
+		Expect(err).To(MatchError("entry has no artifacts"))
+	})
+})
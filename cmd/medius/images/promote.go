@@ -4,30 +4,67 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
 
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
 
 	"kubevirt.io/containerdisks/cmd/medius/common"
 	"kubevirt.io/containerdisks/pkg/api"
+	"kubevirt.io/containerdisks/pkg/architecture"
+	"kubevirt.io/containerdisks/pkg/build"
 	"kubevirt.io/containerdisks/pkg/repository"
 )
 
+const (
+	// ArchPolicyVerified promotes an image index which only contains the verified architectures.
+	ArchPolicyVerified = "verified"
+	// ArchPolicyAllVerified refuses to promote containerdisks unless all architectures were verified.
+	ArchPolicyAllVerified = "all-verified"
+	// ArchPolicyAny promotes all architectures of containerdisks which did not fail verification, including
+	// architectures which were not verified.
+	ArchPolicyAny = "any"
+)
+
+// promotionTarget is a registry containerdisks are promoted to with its resolved settings.
+type promotionTarget struct {
+	Registry string
+	Insecure bool
+	DryRun   bool
+	Repo     repository.Repository
+}
+
 func NewPromoteImagesCommand(options *common.Options) *cobra.Command {
 	options.PromoteImageOptions = common.PromoteImageOptions{
-		TargetRegistry: "quay.io/containerdisks",
+		TargetRegistries: []string{"quay.io/containerdisks"},
+		ArchPolicy:       ArchPolicyAny,
 	}
 
 	promoteCmd := &cobra.Command{
 		Use:   "promote",
-		Short: "Promote verified containerdisks from one registry to another registry",
+		Short: "Promote verified containerdisks from one registry to one or more other registries",
 		Run: func(cmd *cobra.Command, args []string) {
+			switch options.PromoteImageOptions.ArchPolicy {
+			case ArchPolicyVerified, ArchPolicyAllVerified, ArchPolicyAny:
+			default:
+				logrus.Fatalf("unknown architecture policy %q", options.PromoteImageOptions.ArchPolicy)
+			}
+
+			targets, err := promotionTargets(options, cmd.Flags().Changed("target-registry"))
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
 			results, err := readResultsFile(options.ImagesOptions.ResultsFile)
 			if err != nil {
 				logrus.Fatal(err)
 			}
 
+			srcRepo := &repository.RepositoryImpl{Auth: options.RegistryAuth, Network: options.Network}
 			focusMatched, resultsChan, workerErr := spawnWorkers(cmd.Context(), options, func(e *common.Entry) (*api.ArtifactResult, error) {
 				artifact := e.Artifacts[0]
 				description := artifact.Metadata().Describe()
@@ -35,7 +72,7 @@ func NewPromoteImagesCommand(options *common.Options) *cobra.Command {
 				if !ok {
 					return nil, nil
 				}
-				if r.Err != "" {
+				if r.Err != "" && !promotesVerifiedArchitectures(&r, options.PromoteImageOptions.ArchPolicy) {
 					return nil, fmt.Errorf("artifact %s failed in stage %s: %s", description, r.Stage, r.Err)
 				}
 				if r.Stage != StageVerify {
@@ -43,15 +80,18 @@ func NewPromoteImagesCommand(options *common.Options) *cobra.Command {
 				}
 
 				errString := ""
-				err := promoteArtifact(cmd.Context(), artifact, r.Tags, options)
+				digest, targetResults, err := promoteArtifact(cmd.Context(), e, &r, options, srcRepo, targets)
 				if err != nil {
 					errString = err.Error()
 				}
 
 				return &api.ArtifactResult{
-					Tags:  r.Tags,
-					Stage: StagePromote,
-					Err:   errString,
+					Tags:          r.Tags,
+					Digest:        digest,
+					Architectures: r.Architectures,
+					Targets:       targetResults,
+					Stage:         StagePromote,
+					Err:           errString,
 				}, err
 			})
 
@@ -63,7 +103,7 @@ func NewPromoteImagesCommand(options *common.Options) *cobra.Command {
 				logrus.Fatalf("no artifact was processed, focus '%s' did not match", options.Focus)
 			}
 
-			if !options.DryRun {
+			if !allTargetsDryRun(targets) {
 				if err := writeResultsFile(options.ImagesOptions.ResultsFile, results); err != nil {
 					logrus.Fatal(err)
 				}
@@ -76,8 +116,15 @@ func NewPromoteImagesCommand(options *common.Options) *cobra.Command {
 	}
 	promoteCmd.Flags().StringVar(&options.PromoteImageOptions.SourceRegistry, "source-registry",
 		options.PromoteImageOptions.SourceRegistry, "Registry to pull images from")
-	promoteCmd.Flags().StringVar(&options.PromoteImageOptions.TargetRegistry, "target-registry",
-		options.PromoteImageOptions.TargetRegistry, "Registry to promote images to")
+	promoteCmd.Flags().StringSliceVar(&options.PromoteImageOptions.TargetRegistries, "target-registry",
+		options.PromoteImageOptions.TargetRegistries, "Registry to promote images to, can be specified multiple times")
+	promoteCmd.Flags().StringVar(&options.PromoteImageOptions.TargetsFile, "targets-file",
+		options.PromoteImageOptions.TargetsFile, "YAML file with a list of targets to promote images to, including per-target settings")
+	promoteCmd.Flags().StringVar(&options.PromoteImageOptions.ArchPolicy, "arch-policy",
+		options.PromoteImageOptions.ArchPolicy, fmt.Sprintf(
+			"How to handle architectures which were not verified: %q promotes only verified architectures, "+
+				"%q refuses to promote unless all architectures were verified, %q promotes all architectures unless verification failed",
+			ArchPolicyVerified, ArchPolicyAllVerified, ArchPolicyAny))
 
 	err := promoteCmd.MarkFlagRequired("source-registry")
 	if err != nil {
@@ -87,27 +134,259 @@ func NewPromoteImagesCommand(options *common.Options) *cobra.Command {
 	return promoteCmd
 }
 
-func promoteArtifact(ctx context.Context, artifact api.Artifact, tags []string, options *common.Options) error {
-	log := common.Logger(artifact)
+// promotionTargets resolves the targets from the targets file and the --target-registry flag.
+// The default target registry is only used if no targets file was specified.
+func promotionTargets(options *common.Options, targetRegistriesChanged bool) ([]promotionTarget, error) {
+	var configured []common.PromoteTarget
+	if options.PromoteImageOptions.TargetsFile != "" {
+		data, err := os.ReadFile(options.PromoteImageOptions.TargetsFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the targets file: %v", err)
+		}
+		config := common.PromoteTargetsConfig{}
+		if err := yaml.UnmarshalStrict(data, &config); err != nil {
+			return nil, fmt.Errorf("error parsing the targets file: %v", err)
+		}
+		configured = config.Targets
+	}
+	if options.PromoteImageOptions.TargetsFile == "" || targetRegistriesChanged {
+		for _, registry := range options.PromoteImageOptions.TargetRegistries {
+			configured = append(configured, common.PromoteTarget{Registry: registry})
+		}
+	}
+
+	targets := make([]promotionTarget, 0, len(configured))
+	seen := map[string]bool{}
+	for _, t := range configured {
+		if t.Registry == "" {
+			return nil, errors.New("promotion target without registry")
+		}
+		if seen[t.Registry] {
+			return nil, fmt.Errorf("promotion target %q specified more than once", t.Registry)
+		}
+		seen[t.Registry] = true
+
+		targets = append(targets, promotionTarget{
+			Registry: t.Registry,
+			Insecure: ptr.Deref(t.InsecureSkipTLS, options.AllowInsecureRegistry),
+			DryRun:   ptr.Deref(t.DryRun, options.DryRun),
+			Repo: &repository.RepositoryImpl{
+				Auth:    targetAuth(options.RegistryAuth, t.AuthFile),
+				Network: options.Network,
+			},
+		})
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no promotion targets specified")
+	}
+
+	return targets, nil
+}
+
+// targetAuth returns the credential configuration of a target. A per-target auth file replaces
+// the global auth file and default credentials, credentials per registry still apply.
+// It only applies to the target, the source is always read with the global credentials.
+func targetAuth(auth repository.AuthConfig, authFile string) repository.AuthConfig {
+	if authFile != "" {
+		auth.AuthFile = authFile
+		auth.Credentials = nil
+	}
+
+	return auth
+}
+
+func allTargetsDryRun(targets []promotionTarget) bool {
+	for _, target := range targets {
+		if !target.DryRun {
+			return false
+		}
+	}
+
+	return true
+}
+
+// promotedImage is the image or image index which is pushed to all targets.
+type promotedImage struct {
+	image v1.Image
+	index v1.ImageIndex
+}
+
+func (p *promotedImage) digest() (string, error) {
+	var digest v1.Hash
+	var err error
+	if p.index != nil {
+		digest, err = p.index.Digest()
+	} else {
+		digest, err = p.image.Digest()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return digest.String(), nil
+}
+
+func (p *promotedImage) push(ctx context.Context, repo repository.Repository, imgRef string, insecure bool) error {
+	if p.index != nil {
+		return repo.PushImageIndex(ctx, p.index, imgRef, insecure)
+	}
+
+	return repo.PushImage(ctx, p.image, imgRef, insecure)
+}
+
+// promoteArtifact promotes the image referenced by the first tag to all targets. The source digest is resolved
+// once, so all targets and tags point to the same image even if the source tag moves while promoting.
+// Architectures which were not verified are handled according to the architecture policy.
+// The image is read with the settings of the source registry and pushed with the settings of each target.
+// A failing target does not prevent promoting to the remaining targets.
+func promoteArtifact(ctx context.Context, entry *common.Entry, res *api.ArtifactResult, options *common.Options,
+	srcRepo repository.Repository, targets []promotionTarget,
+) (string, map[string]api.TargetResult, error) {
+	log := common.Logger(entry.Artifacts[0])
 
-	if len(tags) == 0 {
+	if len(res.Tags) == 0 {
 		err := errors.New("no containerdisks to promote")
 		log.Error(err)
-		return err
+		return "", nil, err
 	}
 
-	repo := repository.RepositoryImpl{}
-	srcRef := path.Join(options.PromoteImageOptions.SourceRegistry, tags[0])
-	for _, tag := range tags {
-		dstRef := path.Join(options.PromoteImageOptions.TargetRegistry, tag)
-		if !options.DryRun {
-			log.Infof("Copying %s -> %s", srcRef, dstRef)
-			if err := repo.CopyImage(ctx, srcRef, dstRef, options.AllowInsecureRegistry); err != nil {
-				log.WithError(err).Error("Failed to copy image")
-				return err
-			}
+	srcRef := path.Join(options.PromoteImageOptions.SourceRegistry, res.Tags[0])
+	srcDigest, err := srcRepo.ImageDigest(ctx, srcRef, options.AllowInsecureRegistry)
+	if err != nil {
+		log.WithError(err).Error("Failed to resolve source digest")
+		return "", nil, err
+	}
+	srcDigestRef, err := repository.DigestReference(srcRef, srcDigest)
+	if err != nil {
+		return "", nil, err
+	}
+	img, err := sourceImage(ctx, log, entry, res, srcDigestRef, options, srcRepo)
+	if err != nil {
+		log.WithError(err).Error("Failed to select verified architectures")
+		return "", nil, err
+	}
+	digest, err := img.digest()
+	if err != nil {
+		return "", nil, err
+	}
+	log.Infof("Promoting %s with digest %s", srcRef, digest)
+
+	results := map[string]api.TargetResult{}
+	var errs []error
+	for _, target := range targets {
+		result := api.TargetResult{DryRun: target.DryRun}
+		if err := promoteToTarget(ctx, log.WithField("target", target.Registry), img, digest, res.Tags, &target); err != nil {
+			result.Err = err.Error()
+			errs = append(errs, fmt.Errorf("error promoting to %s: %w", target.Registry, err))
+		}
+		results[target.Registry] = result
+
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return digest, results, ctx.Err()
+		}
+	}
+
+	return digest, results, errors.Join(errs...)
+}
+
+// sourceImage reads the image to promote from the source registry and applies the architecture policy.
+// If only some architectures were verified, an image index containing only those is promoted.
+// The filtered index is only pushed to the targets, the source registry is never written to.
+func sourceImage(ctx context.Context, log *logrus.Entry, entry *common.Entry, res *api.ArtifactResult,
+	srcDigestRef string, options *common.Options, srcRepo repository.Repository,
+) (*promotedImage, error) {
+	verified, err := verifiedArchitectures(log, entry, res, options.PromoteImageOptions.ArchPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	// push only creates an image index for containerdisks with multiple architectures
+	if len(entry.Artifacts) == 1 {
+		img, imageErr := srcRepo.Image(ctx, srcDigestRef, options.AllowInsecureRegistry)
+		if imageErr != nil {
+			return nil, imageErr
+		}
+		return &promotedImage{image: img}, nil
+	}
+
+	idx, err := srcRepo.ImageIndex(ctx, srcDigestRef, options.AllowInsecureRegistry)
+	if err != nil {
+		return nil, err
+	}
+	if verified != nil {
+		idx = build.FilterIndex(idx, verified)
+	}
+
+	return &promotedImage{index: idx}, nil
+}
+
+// promotesVerifiedArchitectures returns true if the verified architectures of a containerdisk are promoted even
+// though it failed verification on other architectures.
+func promotesVerifiedArchitectures(res *api.ArtifactResult, policy string) bool {
+	return policy == ArchPolicyVerified && res.Stage == StageVerify && len(res.Architectures) > 0
+}
+
+// verifiedArchitectures applies the architecture policy and returns the architectures to promote.
+// nil means that all architectures are promoted.
+func verifiedArchitectures(log *logrus.Entry, entry *common.Entry, res *api.ArtifactResult, policy string) ([]string, error) {
+	if policy == ArchPolicyAny {
+		return nil, nil
+	}
+	// Results files written before verify recorded architectures only contain verified containerdisks as a whole
+	if len(res.Architectures) == 0 {
+		log.Warn("No verification results per architecture, promoting all architectures")
+		return nil, nil
+	}
+
+	var verified, unverified []string
+	for _, artifact := range entry.Artifacts {
+		arch := architecture.GetImageArchitecture(artifact.Metadata().Arch)
+		if res.Architectures[arch].Verified {
+			verified = append(verified, arch)
 		} else {
-			log.Infof("Dry run enabled, not copying %s -> %s", srcRef, dstRef)
+			unverified = append(unverified, arch)
+		}
+	}
+
+	switch {
+	case len(unverified) == 0:
+		return nil, nil
+	case len(verified) == 0:
+		return nil, errors.New("no architecture was verified")
+	case policy == ArchPolicyAllVerified:
+		return nil, fmt.Errorf("architectures %v were not verified", unverified)
+	}
+
+	log.Warnf("Architectures %v were not verified, promoting only %v", unverified, verified)
+	return verified, nil
+}
+
+func promoteToTarget(ctx context.Context, log *logrus.Entry, img *promotedImage, digest string, tags []string,
+	target *promotionTarget,
+) error {
+	dstRefs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		dstRefs = append(dstRefs, path.Join(target.Registry, tag))
+	}
+
+	if target.DryRun {
+		for _, dstRef := range dstRefs {
+			log.Infof("Dry run enabled, not pushing %s with digest %s", dstRef, digest)
+		}
+		return nil
+	}
+
+	previousDigests, err := targetDigests(ctx, target.Repo, dstRefs, target.Insecure)
+	if err != nil {
+		log.WithError(err).Error("Failed to resolve target digests")
+		return err
+	}
+
+	for _, dstRef := range dstRefs {
+		log.Infof("Pushing %s with digest %s", dstRef, digest)
+		if err = img.push(ctx, target.Repo, dstRef, target.Insecure); err != nil {
+			log.WithError(err).Error("Failed to push image")
+			return err
 		}
 
 		if errors.Is(ctx.Err(), context.Canceled) {
@@ -115,5 +394,75 @@ func promoteArtifact(ctx context.Context, artifact api.Artifact, tags []string,
 		}
 	}
 
+	diverged, err := divergedTags(ctx, target.Repo, dstRefs, digest, target.Insecure)
+	if err != nil {
+		log.WithError(err).Error("Failed to verify promoted tags")
+		return err
+	}
+	if len(diverged) > 0 {
+		rollbackTags(ctx, log, target.Repo, diverged, previousDigests, target.Insecure)
+		return fmt.Errorf("promoted tags %v do not point to digest %s", diverged, digest)
+	}
+
+	log.Infof("All tags point to digest %s", digest)
 	return nil
 }
+
+// targetDigests returns the digests the given references point to before promoting.
+// References which do not exist yet are omitted.
+func targetDigests(ctx context.Context, repo repository.Repository, imgRefs []string, insecure bool) (map[string]string, error) {
+	digests := map[string]string{}
+	for _, imgRef := range imgRefs {
+		digest, err := repo.ImageDigest(ctx, imgRef, insecure)
+		if err != nil {
+			if repository.IsManifestUnknownError(err) || repository.IsRepositoryUnknownError(err) {
+				continue
+			}
+			return nil, err
+		}
+		digests[imgRef] = digest
+	}
+
+	return digests, nil
+}
+
+// divergedTags returns all references which do not resolve to the expected digest.
+func divergedTags(ctx context.Context, repo repository.Repository, imgRefs []string, expected string, insecure bool) ([]string, error) {
+	var diverged []string
+	for _, imgRef := range imgRefs {
+		digest, err := repo.ImageDigest(ctx, imgRef, insecure)
+		if err != nil {
+			return nil, err
+		}
+		if digest != expected {
+			diverged = append(diverged, imgRef)
+		}
+	}
+
+	return diverged, nil
+}
+
+// rollbackTags restores diverged references to the digest they pointed to before promoting.
+// References which did not exist before are only reported.
+func rollbackTags(ctx context.Context, log *logrus.Entry, repo repository.Repository,
+	imgRefs []string, previousDigests map[string]string, insecure bool,
+) {
+	for _, imgRef := range imgRefs {
+		previous, ok := previousDigests[imgRef]
+		if !ok {
+			log.Errorf("Tag %s diverged and did not exist before, it has to be fixed manually", imgRef)
+			continue
+		}
+
+		previousRef, err := repository.DigestReference(imgRef, previous)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to roll back %s", imgRef)
+			continue
+		}
+
+		log.Warnf("Rolling back %s to %s", imgRef, previous)
+		if err := repo.CopyImage(ctx, previousRef, imgRef, insecure); err != nil {
+			log.WithError(err).Errorf("Failed to roll back %s", imgRef)
+		}
+	}
+}
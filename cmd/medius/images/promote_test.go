@@ -0,0 +1,343 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/containerdisks/artifacts/generic"
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+	"kubevirt.io/containerdisks/pkg/build"
+	"kubevirt.io/containerdisks/pkg/repository"
+)
+
+type fakePromoteRepository struct {
+	repository.Repository
+	digests map[string]string
+	pushes  int
+	// moves overwrites references after the given number of pushes to simulate a concurrent push.
+	moves      map[string]string
+	movesAfter int
+	// pushErr is returned for every push if set.
+	pushErr error
+	image   v1.Image
+	index   v1.ImageIndex
+	pushed  map[string]v1.ImageIndex
+}
+
+func (f *fakePromoteRepository) Image(_ context.Context, _ string, _ bool) (v1.Image, error) {
+	return f.image, nil
+}
+
+func (f *fakePromoteRepository) ImageIndex(_ context.Context, _ string, _ bool) (v1.ImageIndex, error) {
+	return f.index, nil
+}
+
+func (f *fakePromoteRepository) PushImage(_ context.Context, img v1.Image, imgRef string, _ bool) error {
+	digest, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	return f.push(imgRef, digest.String())
+}
+
+func (f *fakePromoteRepository) PushImageIndex(_ context.Context, idx v1.ImageIndex, imgRef string, _ bool) error {
+	digest, err := idx.Digest()
+	if err != nil {
+		return err
+	}
+	if err := f.push(imgRef, digest.String()); err != nil {
+		return err
+	}
+	f.pushed[imgRef] = idx
+	return nil
+}
+
+func (f *fakePromoteRepository) push(imgRef, digest string) error {
+	if f.pushErr != nil {
+		return f.pushErr
+	}
+	f.digests[imgRef] = digest
+	f.pushes++
+	if f.pushes == f.movesAfter {
+		for movedRef, movedDigest := range f.moves {
+			f.digests[movedRef] = movedDigest
+		}
+	}
+	return nil
+}
+
+func (f *fakePromoteRepository) ImageDigest(_ context.Context, imgRef string, _ bool) (string, error) {
+	if _, digest, ok := strings.Cut(imgRef, "@"); ok {
+		return digest, nil
+	}
+	digest, ok := f.digests[imgRef]
+	if !ok {
+		return "", &transport.Error{Errors: []transport.Diagnostic{{Code: transport.ManifestUnknownErrorCode}}}
+	}
+	return digest, nil
+}
+
+func (f *fakePromoteRepository) CopyImage(_ context.Context, srcRef, dstRef string, _ bool) error {
+	_, digest, _ := strings.Cut(srcRef, "@")
+	f.digests[dstRef] = digest
+	return nil
+}
+
+var _ = Describe("Promote", func() {
+	tags := []string{"fedora:43-2510190000", "fedora:43"}
+
+	var (
+		options     *common.Options
+		entry       *common.Entry
+		res         *api.ArtifactResult
+		srcRepo     *fakePromoteRepository
+		imageDigest string
+	)
+
+	BeforeEach(func() {
+		options = &common.Options{
+			PromoteImageOptions: common.PromoteImageOptions{
+				SourceRegistry: "source",
+				ArchPolicy:     ArchPolicyVerified,
+			},
+		}
+		entry = &common.Entry{
+			Artifacts: []api.Artifact{
+				generic.New(&api.ArtifactDetails{}, &api.Metadata{Name: "fedora", Version: "43", Arch: "x86_64"}),
+			},
+		}
+		res = &api.ArtifactResult{
+			Tags: tags,
+			Architectures: map[string]api.ArchitectureResult{
+				"amd64": {Verified: true},
+			},
+		}
+
+		img, err := random.Image(1, 1)
+		Expect(err).ToNot(HaveOccurred())
+		digest, err := img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		imageDigest = digest.String()
+		srcRepo = &fakePromoteRepository{
+			digests: map[string]string{
+				"source/fedora:43-2510190000": imageDigest,
+			},
+			image:  img,
+			pushed: map[string]v1.ImageIndex{},
+		}
+	})
+
+	It("should push all tags with the source digest", func() {
+		repo := &fakePromoteRepository{
+			digests: map[string]string{
+				"target/fedora:43": "sha256:old",
+			},
+		}
+
+		targets := []promotionTarget{{Registry: "target", Repo: repo}}
+		digest, results, err := promoteArtifact(context.Background(), entry, res, options, srcRepo, targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(digest).To(Equal(imageDigest))
+		Expect(results).To(Equal(map[string]api.TargetResult{"target": {}}))
+		Expect(repo.digests).To(HaveKeyWithValue("target/fedora:43-2510190000", imageDigest))
+		Expect(repo.digests).To(HaveKeyWithValue("target/fedora:43", imageDigest))
+		Expect(srcRepo.pushes).To(BeZero())
+	})
+
+	It("should roll back and report tags which diverge from the promoted digest", func() {
+		repo := &fakePromoteRepository{
+			digests: map[string]string{
+				"target/fedora:43": "sha256:old",
+			},
+			moves: map[string]string{
+				"target/fedora:43": "sha256:other",
+			},
+			movesAfter: 2,
+		}
+
+		targets := []promotionTarget{{Registry: "target", Repo: repo}}
+		digest, results, err := promoteArtifact(context.Background(), entry, res, options, srcRepo, targets)
+		Expect(err).To(MatchError(ContainSubstring("target/fedora:43]")))
+		Expect(results["target"].Err).To(ContainSubstring("target/fedora:43]"))
+		Expect(digest).To(Equal(imageDigest))
+		Expect(repo.digests).To(HaveKeyWithValue("target/fedora:43-2510190000", imageDigest))
+		Expect(repo.digests).To(HaveKeyWithValue("target/fedora:43", "sha256:old"))
+	})
+
+	It("should not push anything to dry run targets", func() {
+		repo := &fakePromoteRepository{digests: map[string]string{}}
+
+		targets := []promotionTarget{{Registry: "target", Repo: repo, DryRun: true}}
+		digest, results, err := promoteArtifact(context.Background(), entry, res, options, srcRepo, targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(digest).To(Equal(imageDigest))
+		Expect(results).To(Equal(map[string]api.TargetResult{"target": {DryRun: true}}))
+		Expect(repo.digests).To(BeEmpty())
+	})
+
+	It("should continue promoting to other targets if one target fails", func() {
+		repo := &fakePromoteRepository{digests: map[string]string{}}
+		brokenRepo := &fakePromoteRepository{
+			digests: map[string]string{},
+			pushErr: errors.New("registry unavailable"),
+		}
+
+		targets := []promotionTarget{
+			{Registry: "broken", Repo: brokenRepo},
+			{Registry: "target", Repo: repo},
+		}
+		digest, results, err := promoteArtifact(context.Background(), entry, res, options, srcRepo, targets)
+		Expect(err).To(MatchError(ContainSubstring("error promoting to broken: registry unavailable")))
+		Expect(digest).To(Equal(imageDigest))
+		Expect(results).To(Equal(map[string]api.TargetResult{
+			"broken": {Err: "registry unavailable"},
+			"target": {},
+		}))
+		Expect(repo.digests).To(HaveKeyWithValue("target/fedora:43-2510190000", imageDigest))
+		Expect(repo.digests).To(HaveKeyWithValue("target/fedora:43", imageDigest))
+	})
+
+	Context("with multiple architectures", func() {
+		var (
+			repo          *fakePromoteRepository
+			indexDigest   string
+			filteredIndex v1.ImageIndex
+		)
+
+		BeforeEach(func() {
+			entry.Artifacts = append(entry.Artifacts,
+				generic.New(&api.ArtifactDetails{}, &api.Metadata{Name: "fedora", Version: "43", Arch: "aarch64"}))
+
+			var addenda []mutate.IndexAddendum
+			for _, arch := range []string{"amd64", "arm64"} {
+				img, err := random.Image(1, 1)
+				Expect(err).ToNot(HaveOccurred())
+				addenda = append(addenda, mutate.IndexAddendum{
+					Add: img,
+					Descriptor: v1.Descriptor{
+						Platform: &v1.Platform{OS: "linux", Architecture: arch},
+					},
+				})
+			}
+			index := mutate.AppendManifests(empty.Index, addenda...)
+			digest, err := index.Digest()
+			Expect(err).ToNot(HaveOccurred())
+			indexDigest = digest.String()
+			filteredIndex = build.FilterIndex(index, []string{"amd64"})
+
+			srcRepo.digests["source/fedora:43-2510190000"] = indexDigest
+			srcRepo.index = index
+			repo = &fakePromoteRepository{
+				digests: map[string]string{},
+				pushed:  map[string]v1.ImageIndex{},
+			}
+		})
+
+		It("should promote an index with only the verified architectures to the targets only", func() {
+			filteredDigest, err := filteredIndex.Digest()
+			Expect(err).ToNot(HaveOccurred())
+
+			targets := []promotionTarget{{Registry: "target", Repo: repo}}
+			digest, _, err := promoteArtifact(context.Background(), entry, res, options, srcRepo, targets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(digest).To(Equal(filteredDigest.String()))
+			Expect(repo.digests).To(HaveKeyWithValue("target/fedora:43", filteredDigest.String()))
+			Expect(srcRepo.pushed).To(BeEmpty())
+
+			Expect(repo.pushed).To(HaveLen(len(tags)))
+			for _, idx := range repo.pushed {
+				manifest, err := idx.IndexManifest()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(manifest.Manifests).To(HaveLen(1))
+				Expect(manifest.Manifests[0].Platform.Architecture).To(Equal("amd64"))
+			}
+		})
+
+		It("should promote the whole index if the results have no architectures", func() {
+			res.Architectures = nil
+
+			targets := []promotionTarget{{Registry: "target", Repo: repo}}
+			digest, _, err := promoteArtifact(context.Background(), entry, res, options, srcRepo, targets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(digest).To(Equal(indexDigest))
+			Expect(repo.digests).To(HaveKeyWithValue("target/fedora:43", indexDigest))
+		})
+
+		It("should refuse to promote if not all architectures were verified", func() {
+			options.PromoteImageOptions.ArchPolicy = ArchPolicyAllVerified
+
+			targets := []promotionTarget{{Registry: "target", Repo: repo}}
+			_, _, err := promoteArtifact(context.Background(), entry, res, options, srcRepo, targets)
+			Expect(err).To(MatchError("architectures [arm64] were not verified"))
+			Expect(repo.pushed).To(BeEmpty())
+		})
+
+		It("should promote the whole index regardless of verification", func() {
+			options.PromoteImageOptions.ArchPolicy = ArchPolicyAny
+
+			targets := []promotionTarget{{Registry: "target", Repo: repo}}
+			digest, _, err := promoteArtifact(context.Background(), entry, res, options, srcRepo, targets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(digest).To(Equal(indexDigest))
+			Expect(repo.digests).To(HaveKeyWithValue("target/fedora:43", indexDigest))
+		})
+	})
+
+	It("promotionTargets should resolve per-target settings", func() {
+		targetsFile := filepath.Join(GinkgoT().TempDir(), "targets.yaml")
+		Expect(os.WriteFile(targetsFile, []byte(`targets:
+- registry: quay.io/containerdisks
+  authFile: /auth.json
+  dryRun: false
+- registry: internal.example.com/containerdisks
+  insecureSkipTLS: true
+- registry: secure.example.com/containerdisks
+  insecureSkipTLS: false
+`), 0o600)).To(Succeed())
+		options.AllowInsecureRegistry = true
+		options.RegistryAuth = repository.AuthConfig{Credentials: &repository.Credentials{Username: "user", Password: "secret"}}
+		options.DryRun = true
+		options.PromoteImageOptions.TargetsFile = targetsFile
+		options.PromoteImageOptions.TargetRegistries = []string{"mirror.example.com/containerdisks"}
+
+		targets, err := promotionTargets(options, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targets).To(Equal([]promotionTarget{
+			{
+				Registry: "quay.io/containerdisks",
+				Insecure: true,
+				DryRun:   false,
+				Repo:     &repository.RepositoryImpl{Auth: repository.AuthConfig{AuthFile: "/auth.json"}},
+			},
+			{
+				Registry: "internal.example.com/containerdisks",
+				Insecure: true,
+				DryRun:   true,
+				Repo:     &repository.RepositoryImpl{Auth: options.RegistryAuth},
+			},
+			{
+				Registry: "secure.example.com/containerdisks",
+				DryRun:   true,
+				Repo:     &repository.RepositoryImpl{Auth: options.RegistryAuth},
+			},
+			{
+				Registry: "mirror.example.com/containerdisks",
+				Insecure: true,
+				DryRun:   true,
+				Repo:     &repository.RepositoryImpl{Auth: options.RegistryAuth},
+			},
+		}))
+	})
+})
@@ -0,0 +1,257 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+	"kubevirt.io/containerdisks/pkg/repository"
+)
+
+const day = 24 * time.Hour
+
+// timestampTagRegExp matches tags created by buildPlan.Tags, e.g. "43-2510191200".
+var timestampTagRegExp = regexp.MustCompile(`^(.+)-(\d{10})$`)
+
+type timestampTag struct {
+	Tag     string
+	Created time.Time
+}
+
+type pruneImages struct {
+	Ctx     context.Context
+	Log     *logrus.Entry
+	Options *common.Options
+	Repo    repository.Repository
+}
+
+func NewPruneImagesCommand(options *common.Options) *cobra.Command {
+	const (
+		defaultKeepLast = 5
+		defaultKeepDays = 30
+	)
+
+	options.PruneImageOptions = common.PruneImageOptions{
+		Registry: "quay.io/containerdisks",
+		KeepLast: defaultKeepLast,
+		KeepDays: defaultKeepDays,
+	}
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old timestamped tags of containerdisks according to the retention policy",
+		Run: func(cmd *cobra.Command, args []string) {
+			now := time.Now()
+			focusMatched, _, workerErr := spawnWorkers(cmd.Context(), options, func(e *common.Entry) (*api.ArtifactResult, error) {
+				p := pruneImages{
+					Ctx:     cmd.Context(),
+					Log:     common.Logger(e.Artifacts[0]),
+					Options: options,
+					Repo:    &repository.RepositoryImpl{Auth: options.RegistryAuth, Network: options.Network},
+				}
+				_, err := p.Do(e, now)
+				return nil, err
+			})
+
+			if !focusMatched {
+				logrus.Fatalf("no artifact was processed, focus '%s' did not match", options.Focus)
+			}
+
+			if workerErr != nil {
+				logrus.Fatal(workerErr)
+			}
+		},
+	}
+	pruneCmd.Flags().StringVar(&options.PruneImageOptions.Registry, "registry",
+		options.PruneImageOptions.Registry, "Registry to prune containerdisk tags from")
+	pruneCmd.Flags().IntVar(&options.PruneImageOptions.KeepLast, "keep-last",
+		options.PruneImageOptions.KeepLast, "Number of most recent timestamped tags to keep per moving tag")
+	pruneCmd.Flags().IntVar(&options.PruneImageOptions.KeepDays, "keep-days",
+		options.PruneImageOptions.KeepDays, "Keep timestamped tags which are younger than the given number of days")
+
+	return pruneCmd
+}
+
+// Do deletes the timestamped tags of the entry's moving tag which are not covered by the retention policy.
+// Tags pointing to the same image as a moving tag, an additional unique tag, latest or a retained
+// timestamped tag of any moving tag in the repository are never deleted. Images are deleted by digest,
+// which also removes the other pruned tags pointing to them. It returns the pruned tags.
+func (p *pruneImages) Do(entry *common.Entry, now time.Time) ([]string, error) {
+	plan, err := newBuildPlan(entry)
+	if err != nil {
+		return nil, err
+	}
+	metadata := plan.Metadata(0)
+	uniqueTags := plan.Artifacts()[0].AdditionalUniqueTags
+	repo := path.Join(p.Options.PruneImageOptions.Registry, metadata.Name)
+
+	tags, err := p.Repo.ListTags(p.Ctx, repo, p.Options.AllowInsecureRegistry)
+	if err != nil {
+		if repository.IsRepositoryUnknownError(err) {
+			p.Log.Info("Repository does not exist, nothing to prune")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing tags of %q: %v", repo, err)
+	}
+
+	// Additional unique tags are never pruned, even if they look like timestamped tags
+	timestamped := slices.DeleteFunc(slices.Clone(tags), func(tag string) bool {
+		return slices.Contains(uniqueTags, tag)
+	})
+	_, prune := p.partition(timestampTagsOf(timestamped, metadata.Version), now)
+	if len(prune) == 0 {
+		p.Log.Info("Nothing to prune.")
+		return nil, nil
+	}
+
+	protected, err := p.protectedDigests(repo, tags, uniqueTags, now)
+	if err != nil {
+		return nil, err
+	}
+	// All digests are resolved before deleting, deleting a manifest removes every tag pointing to it
+	digests, err := p.tagDigests(repo, timestampTagNames(prune))
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []string
+	deleted := map[string]bool{}
+	for _, t := range prune {
+		imgRef := fmt.Sprintf("%s:%s", repo, t.Tag)
+		digest := digests[t.Tag]
+		if protected[digest] {
+			p.Log.Infof("Keeping %s, it is still referenced by another tag", imgRef)
+			continue
+		}
+
+		switch {
+		case p.Options.DryRun:
+			p.Log.Infof("Dry run enabled, not deleting %s", imgRef)
+		case deleted[digest]:
+			p.Log.Infof("Deleted %s together with %s", imgRef, digest)
+		default:
+			p.Log.Infof("Deleting %s (%s)", imgRef, digest)
+			if err := p.Repo.DeleteManifest(p.Ctx, fmt.Sprintf("%s@%s", repo, digest), p.Options.AllowInsecureRegistry); err != nil {
+				p.Log.WithError(err).Error("Failed to delete manifest")
+				return pruned, err
+			}
+			deleted[digest] = true
+		}
+		pruned = append(pruned, t.Tag)
+
+		if errors.Is(p.Ctx.Err(), context.Canceled) {
+			return pruned, p.Ctx.Err()
+		}
+	}
+
+	return pruned, nil
+}
+
+// protectedDigests returns the digests of all images which are referenced by non-timestamped tags (moving tags,
+// latest), by uniqueTags or by the retained timestamped tags of any moving tag in the repository.
+func (p *pruneImages) protectedDigests(repo string, tags, uniqueTags []string, now time.Time) (map[string]bool, error) {
+	var protectedTags []string
+	timestamped := map[string][]string{}
+	for _, tag := range tags {
+		matches := timestampTagRegExp.FindStringSubmatch(tag)
+		if matches == nil || slices.Contains(uniqueTags, tag) {
+			protectedTags = append(protectedTags, tag)
+			continue
+		}
+		timestamped[matches[1]] = append(timestamped[matches[1]], tag)
+	}
+	// Other moving tags in the repository are pruned with the same retention policy
+	for movingTag, movingTagTags := range timestamped {
+		keep, _ := p.partition(timestampTagsOf(movingTagTags, movingTag), now)
+		protectedTags = append(protectedTags, timestampTagNames(keep)...)
+	}
+
+	digests, err := p.tagDigests(repo, protectedTags)
+	if err != nil {
+		return nil, err
+	}
+
+	protected := map[string]bool{}
+	for _, digest := range digests {
+		protected[digest] = true
+	}
+
+	return protected, nil
+}
+
+// tagDigests resolves the digests of the given tags of repo.
+func (p *pruneImages) tagDigests(repo string, tags []string) (map[string]string, error) {
+	digests := map[string]string{}
+	for _, tag := range tags {
+		imgRef := fmt.Sprintf("%s:%s", repo, tag)
+		digest, err := p.Repo.ImageDigest(p.Ctx, imgRef, p.Options.AllowInsecureRegistry)
+		if err != nil {
+			return nil, fmt.Errorf("error getting digest of %q: %v", imgRef, err)
+		}
+		digests[tag] = digest
+	}
+
+	return digests, nil
+}
+
+func timestampTagNames(tags []timestampTag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Tag)
+	}
+
+	return names
+}
+
+// timestampTagsOf returns the timestamped tags belonging to the given moving tag sorted from newest to oldest.
+func timestampTagsOf(tags []string, movingTag string) []timestampTag {
+	var result []timestampTag
+	for _, tag := range tags {
+		matches := timestampTagRegExp.FindStringSubmatch(tag)
+		if len(matches) == 0 || matches[1] != movingTag {
+			continue
+		}
+		created, err := time.ParseInLocation(tagTimestampFormat, matches[2], time.Local)
+		if err != nil {
+			continue
+		}
+		result = append(result, timestampTag{Tag: tag, Created: created})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Created.After(result[j].Created)
+	})
+
+	return result
+}
+
+// partition splits tags sorted from newest to oldest into tags to keep and tags to prune with the retention
+// policy of the options.
+func (p *pruneImages) partition(tags []timestampTag, now time.Time) (keep, prune []timestampTag) {
+	return partitionTimestampTags(tags, p.Options.PruneImageOptions.KeepLast,
+		time.Duration(p.Options.PruneImageOptions.KeepDays)*day, now)
+}
+
+// partitionTimestampTags splits tags sorted from newest to oldest into tags to keep and tags to prune.
+// The first keepLast tags and all tags younger than keepAge are kept.
+func partitionTimestampTags(tags []timestampTag, keepLast int, keepAge time.Duration, now time.Time) (keep, prune []timestampTag) {
+	for i, t := range tags {
+		if i < keepLast || now.Sub(t.Created) < keepAge {
+			keep = append(keep, t)
+		} else {
+			prune = append(prune, t)
+		}
+	}
+
+	return keep, prune
+}
@@ -0,0 +1,195 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+
+	"kubevirt.io/containerdisks/artifacts/generic"
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+	"kubevirt.io/containerdisks/pkg/repository"
+)
+
+type fakeTagRepository struct {
+	repository.Repository
+	digests map[string]string
+	deleted []string
+}
+
+func (f *fakeTagRepository) ListTags(_ context.Context, _ string, _ bool) ([]string, error) {
+	var tags []string
+	for tag := range f.digests {
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (f *fakeTagRepository) ImageDigest(_ context.Context, imgRef string, _ bool) (string, error) {
+	digest, ok := f.digests[imgRef[len("registry/fedora:"):]]
+	if !ok {
+		return "", fmt.Errorf("manifest unknown: %s", imgRef)
+	}
+	return digest, nil
+}
+
+// DeleteManifest removes all tags pointing to the digest like a registry does.
+func (f *fakeTagRepository) DeleteManifest(_ context.Context, imgRef string, _ bool) error {
+	f.deleted = append(f.deleted, imgRef)
+	maps.DeleteFunc(f.digests, func(_, digest string) bool {
+		return digest == imgRef[len("registry/fedora@"):]
+	})
+	return nil
+}
+
+var _ = Describe("Prune", func() {
+	now := time.Date(2025, 10, 19, 12, 0, 0, 0, time.Local)
+
+	It("timestampTagsOf should only return timestamped tags of the moving tag sorted by age", func() {
+		tags := timestampTagsOf([]string{
+			"43", "43-1.6", "latest", "43-2510010000", "43-2510190000", "44-2510150000", "43-beta-2510100000", "43-9999999999",
+		}, "43")
+		Expect(tags).To(Equal([]timestampTag{
+			{Tag: "43-2510190000", Created: time.Date(2025, 10, 19, 0, 0, 0, 0, time.Local)},
+			{Tag: "43-2510010000", Created: time.Date(2025, 10, 1, 0, 0, 0, 0, time.Local)},
+		}))
+	})
+
+	DescribeTable("partitionTimestampTags should apply the retention policy",
+		func(keepLast, keepDays int, expectedKeep int) {
+			tags := []timestampTag{
+				{Tag: "43-2510190000", Created: now.Add(-12 * time.Hour)},
+				{Tag: "43-2510150000", Created: now.Add(-4 * day)},
+				{Tag: "43-2510010000", Created: now.Add(-18 * day)},
+				{Tag: "43-2509010000", Created: now.Add(-48 * day)},
+			}
+			keep, prune := partitionTimestampTags(tags, keepLast, time.Duration(keepDays)*day, now)
+			Expect(keep).To(HaveExactElements(tags[:expectedKeep]))
+			Expect(prune).To(HaveExactElements(tags[expectedKeep:]))
+		},
+		Entry("keep last only", 2, 0, 2),
+		Entry("keep younger than days only", 0, 7, 2),
+		Entry("keep days exceeds keep last", 1, 30, 3),
+		Entry("keep everything", 10, 0, 4),
+	)
+
+	It("Do should never delete tags referenced by other tags", func() {
+		repo := &fakeTagRepository{
+			digests: map[string]string{
+				"43":            "sha256:d",
+				"latest":        "sha256:d",
+				"43-1.5":        "sha256:b",
+				"43-2510190000": "sha256:d",
+				"43-2510150000": "sha256:c",
+				"43-2510010000": "sha256:b",
+				"43-2509010000": "sha256:a",
+				"43-2508010000": "sha256:a",
+			},
+		}
+		p := pruneImages{
+			Ctx: context.Background(),
+			Log: logrus.NewEntry(logrus.StandardLogger()),
+			Options: &common.Options{
+				PruneImageOptions: common.PruneImageOptions{
+					Registry: "registry",
+					KeepLast: 1,
+				},
+			},
+			Repo: repo,
+		}
+		entry := &common.Entry{
+			Artifacts: []api.Artifact{
+				generic.New(&api.ArtifactDetails{}, &api.Metadata{Name: "fedora", Version: "43"}),
+			},
+		}
+
+		pruned, err := p.Do(entry, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pruned).To(ConsistOf("43-2510150000", "43-2509010000", "43-2508010000"))
+		Expect(repo.deleted).To(ConsistOf("registry/fedora@sha256:c", "registry/fedora@sha256:a"))
+	})
+
+	It("Do should protect the tags of other moving tags and additional unique tags", func() {
+		repo := &fakeTagRepository{
+			digests: map[string]string{
+				"43":            "sha256:d",
+				"44":            "sha256:e",
+				"43-2510190000": "sha256:d",
+				"43-2509010000": "sha256:a",
+				"43-2508010000": "sha256:b",
+				"43-2501010000": "sha256:f",
+				"44-2510190000": "sha256:a",
+				"44-2509010000": "sha256:c",
+			},
+		}
+		p := pruneImages{
+			Ctx: context.Background(),
+			Log: logrus.NewEntry(logrus.StandardLogger()),
+			Options: &common.Options{
+				PruneImageOptions: common.PruneImageOptions{
+					Registry: "registry",
+					KeepLast: 1,
+				},
+			},
+			Repo: repo,
+		}
+		entry := &common.Entry{
+			Artifacts: []api.Artifact{
+				generic.New(
+					&api.ArtifactDetails{AdditionalUniqueTags: []string{"43-2501010000"}},
+					&api.Metadata{Name: "fedora", Version: "43"},
+				),
+			},
+		}
+
+		pruned, err := p.Do(entry, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pruned).To(ConsistOf("43-2508010000"))
+		Expect(repo.deleted).To(ConsistOf("registry/fedora@sha256:b"))
+		Expect(repo.digests).To(HaveKey("43-2501010000"))
+		Expect(repo.digests).To(HaveKey("44-2509010000"))
+	})
+
+	It("Do should not delete anything in dry run mode", func() {
+		repo := &fakeTagRepository{
+			digests: map[string]string{
+				"43":            "sha256:b",
+				"43-2510190000": "sha256:b",
+				"43-2509010000": "sha256:a",
+			},
+		}
+		p := pruneImages{
+			Ctx: context.Background(),
+			Log: logrus.NewEntry(logrus.StandardLogger()),
+			Options: &common.Options{
+				DryRun: true,
+				PruneImageOptions: common.PruneImageOptions{
+					Registry: "registry",
+					KeepLast: 1,
+				},
+			},
+			Repo: repo,
+		}
+		entry := &common.Entry{
+			Artifacts: []api.Artifact{
+				generic.New(&api.ArtifactDetails{}, &api.Metadata{Name: "fedora", Version: "43"}),
+			},
+		}
+
+		pruned, err := p.Do(entry, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pruned).To(ConsistOf("43-2509010000"))
+		Expect(repo.deleted).To(BeEmpty())
+	})
+})
+
+func TestImages(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Images Suite")
+}
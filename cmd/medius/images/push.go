@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path"
 	"time"
@@ -15,25 +16,34 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/ulikunitz/xz"
 	"go.podman.io/image/v5/pkg/compression/types"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"kubevirt.io/containerdisks/cmd/medius/common"
 	"kubevirt.io/containerdisks/pkg/api"
 	"kubevirt.io/containerdisks/pkg/build"
+	"kubevirt.io/containerdisks/pkg/cache"
 	"kubevirt.io/containerdisks/pkg/http"
 	"kubevirt.io/containerdisks/pkg/repository"
 )
 
+// tagTimestampFormat is the format of the timestamp suffix of the unique tag created on every push.
+const tagTimestampFormat = "0601021504"
+
 type buildAndPublish struct {
 	Ctx     context.Context
 	Log     *logrus.Entry
 	Options *common.Options
 	Repo    repository.Repository
 	Getter  http.Getter
+	// Cache stores downloaded artifacts across runs, it is disabled if nil.
+	Cache *cache.Cache
 }
 
 func NewPublishImagesCommand(options *common.Options) *cobra.Command {
 	options.PublishImagesOptions = common.PublishImageOptions{
-		SourceRegistry: "quay.io/containerdisks",
+		SourceRegistry:      "quay.io/containerdisks",
+		DownloadConnections: 1,
+		CacheMaxSize:        "50Gi",
 	}
 
 	publishCmd := &cobra.Command{
@@ -44,16 +54,23 @@ func NewPublishImagesCommand(options *common.Options) *cobra.Command {
 				options.PublishImagesOptions.TargetRegistry = options.PublishImagesOptions.SourceRegistry
 			}
 
+			artifactCache, err := newArtifactCache(&options.PublishImagesOptions)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
 			focusMatched, resultsChan, workerErr := spawnWorkers(cmd.Context(), options, func(e *common.Entry) (*api.ArtifactResult, error) {
 				errString := ""
 				artifact := e.Artifacts[0]
 
+				log := common.Logger(artifact)
 				b := buildAndPublish{
 					Ctx:     cmd.Context(),
-					Log:     common.Logger(artifact),
+					Log:     log,
 					Options: options,
-					Repo:    &repository.RepositoryImpl{},
-					Getter:  &http.HTTPGetter{},
+					Repo:    &repository.RepositoryImpl{Auth: options.RegistryAuth, Network: options.Network},
+					Getter:  downloadGetter(options, log, artifactCache),
+					Cache:   artifactCache,
 				}
 				tags, err := b.Do(e, time.Now())
 				if err != nil {
@@ -99,6 +116,18 @@ func NewPublishImagesCommand(options *common.Options) *cobra.Command {
 		options.PublishImagesOptions.ForceBuild, "Force a rebuild and push")
 	publishCmd.Flags().BoolVar(&options.PublishImagesOptions.NoFail, "no-fail",
 		options.PublishImagesOptions.NoFail, "Return success even if a worker fails")
+	publishCmd.Flags().IntVar(&options.PublishImagesOptions.DownloadConnections, "download-connections",
+		options.PublishImagesOptions.DownloadConnections, "Number of parallel range requests per upstream download")
+	publishCmd.Flags().StringToIntVar(&options.PublishImagesOptions.DownloadConnectionsPerHost, "download-connections-per-host",
+		options.PublishImagesOptions.DownloadConnectionsPerHost,
+		"Number of parallel range requests per upstream host, e.g. download.fedoraproject.org=4")
+	publishCmd.Flags().StringVar(&options.PublishImagesOptions.CacheDir, "cache-dir",
+		options.PublishImagesOptions.CacheDir, "Directory to cache downloaded disks across runs, caching is disabled if empty")
+	publishCmd.Flags().StringVar(&options.PublishImagesOptions.CacheMaxSize, "cache-max-size",
+		options.PublishImagesOptions.CacheMaxSize, "Maximum size of the cache, least recently used disks are evicted first")
+	publishCmd.Flags().StringToStringVar(&options.PublishImagesOptions.MirrorOverrides, "mirror-override",
+		options.PublishImagesOptions.MirrorOverrides,
+		"Mirror to download a containerdisk from first, e.g. fedora=https://artifactory.example.com/fedora")
 	publishCmd.Flags().StringVar(&options.PublishImagesOptions.SourceRegistry, "source-registry",
 		options.PublishImagesOptions.SourceRegistry, "Registry to check if updates are needed")
 	publishCmd.Flags().StringVar(&options.PublishImagesOptions.TargetRegistry, "target-registry",
@@ -108,13 +137,12 @@ func NewPublishImagesCommand(options *common.Options) *cobra.Command {
 }
 
 func (b *buildAndPublish) Do(entry *common.Entry, timestamp time.Time) ([]string, error) {
-	metadata := entry.Artifacts[0].Metadata()
-	artifactInfo, err := entry.Artifacts[0].Inspect()
+	plan, err := newBuildPlan(entry)
 	if err != nil {
-		return nil, fmt.Errorf("error introspecting artifact %q: %v", metadata.Describe(), err)
+		return nil, err
 	}
 
-	rebuildNeeded, err := b.rebuildNeeded(entry)
+	rebuildNeeded, err := b.rebuildNeeded(plan)
 	if err != nil {
 		return nil, err
 	}
@@ -126,13 +154,13 @@ func (b *buildAndPublish) Do(entry *common.Entry, timestamp time.Time) ([]string
 		return nil, b.Ctx.Err()
 	}
 
-	images, artifacts, err := b.buildImages(entry)
+	images, artifacts, err := b.buildImages(plan)
 	if err != nil {
 		return nil, err
 	}
 	defer cleanupArtifacts(artifacts)
 
-	names := prepareTags(timestamp, b.Options.PublishImagesOptions.TargetRegistry, entry, artifactInfo)
+	names := plan.Tags(timestamp, b.Options.PublishImagesOptions.TargetRegistry)
 	for _, name := range names {
 		if len(images) > 1 {
 			containerDiskIndex, err := build.ContainerDiskIndex(images)
@@ -152,7 +180,7 @@ func (b *buildAndPublish) Do(entry *common.Entry, timestamp time.Time) ([]string
 		}
 	}
 
-	return prepareTags(timestamp, "", entry, artifactInfo), nil
+	return plan.Tags(timestamp, ""), nil
 }
 
 func (b *buildAndPublish) getImageChecksum(description, arch string) (imageChecksum string, err error) {
@@ -178,6 +206,8 @@ func (b *buildAndPublish) handleMetadataError(imageName string, err error) error
 		b.Log.Info("Tag is gone but seems to have existed already, it will be created")
 	case repository.IsArchUnknownError(err):
 		b.Log.Info("Image with arch does not exist yet, it will be created")
+	case repository.IsAuthError(err):
+		return fmt.Errorf("error authenticating to the registry for image %q: %v", imageName, err)
 	default:
 		return fmt.Errorf("error introspecting image %q: %v", imageName, err)
 	}
@@ -185,8 +215,101 @@ func (b *buildAndPublish) handleMetadataError(imageName string, err error) error
 	return nil
 }
 
-func (b *buildAndPublish) getArtifact(artifactInfo *api.ArtifactDetails) (string, error) {
-	artifactReader, err := b.getArtifactReader(artifactInfo)
+func newArtifactCache(options *common.PublishImageOptions) (*cache.Cache, error) {
+	if options.CacheDir == "" {
+		return nil, nil
+	}
+
+	maxSize, err := resource.ParseQuantity(options.CacheMaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache size %q: %v", options.CacheMaxSize, err)
+	}
+
+	return cache.New(options.CacheDir, maxSize.Value())
+}
+
+// downloadGetter returns the upstream getter of the options configured for downloading disks.
+func downloadGetter(options *common.Options, log *logrus.Entry, artifactCache *cache.Cache) *http.HTTPGetter {
+	getter := options.Upstream
+	getter.Log = log
+	getter.Connections = options.PublishImagesOptions.DownloadConnectionsPerHost
+	getter.DefaultConnections = options.PublishImagesOptions.DownloadConnections
+	if artifactCache != nil {
+		// Parallel downloads are stored next to the files readArtifact writes
+		getter.TempDir = artifactCache.TempDir()
+	}
+
+	return &getter
+}
+
+func (b *buildAndPublish) getArtifact(metadata *api.Metadata, artifactInfo *api.ArtifactDetails) (string, error) {
+	if b.Cache != nil && artifactInfo.Checksum != "" {
+		file, err := b.Cache.Get(artifactInfo.Checksum)
+		if err != nil {
+			b.Log.Warnf("Error reading the artifact from the cache: %v", err)
+		} else if file != "" {
+			b.Log.Infof("Using cached artifact with checksum %q", artifactInfo.Checksum)
+			return file, nil
+		}
+	}
+
+	downloadURLs, err := b.downloadURLs(metadata, artifactInfo)
+	if err != nil {
+		return "", err
+	}
+
+	var errs []error
+	for _, downloadURL := range downloadURLs {
+		b.Log.Infof("Downloading %q ...", downloadURL)
+		file, err := b.downloadArtifact(downloadURL, artifactInfo)
+		if err == nil {
+			if b.Cache != nil {
+				if cacheErr := b.Cache.Put(artifactInfo.Checksum, file); cacheErr != nil {
+					b.Log.Warnf("Error adding the artifact to the cache: %v", cacheErr)
+				}
+			}
+			return file, nil
+		}
+		if errors.Is(err, context.Canceled) {
+			return "", err
+		}
+		b.Log.Warnf("Download from %q failed: %v", downloadURL, err)
+		errs = append(errs, err)
+	}
+
+	return "", errors.Join(errs...)
+}
+
+// downloadURLs returns the URLs an artifact is downloaded from in the order they are tried.
+// A configured mirror override comes first, followed by the upstream URL and its mirrors.
+func (b *buildAndPublish) downloadURLs(metadata *api.Metadata, artifactInfo *api.ArtifactDetails) ([]string, error) {
+	var downloadURLs []string
+	if mirror, ok := b.Options.PublishImagesOptions.MirrorOverrides[metadata.Name]; ok {
+		mirrorURL, err := overrideMirror(mirror, artifactInfo.DownloadURL)
+		if err != nil {
+			return nil, err
+		}
+		downloadURLs = append(downloadURLs, mirrorURL)
+	}
+
+	return append(append(downloadURLs, artifactInfo.DownloadURL), artifactInfo.MirrorURLs...), nil
+}
+
+// overrideMirror replaces scheme and host of downloadURL with mirror, e.g. with a mirror
+// "https://artifactory.example.com/fedora" the URL "https://download.fedoraproject.org/pub/fedora/disk.qcow2"
+// becomes "https://artifactory.example.com/fedora/pub/fedora/disk.qcow2".
+func overrideMirror(mirror, downloadURL string) (string, error) {
+	parsedURL, err := url.Parse(downloadURL)
+	if err != nil {
+		return "", fmt.Errorf("error parsing the download URL: %v", err)
+	}
+
+	return url.JoinPath(mirror, parsedURL.Path)
+}
+
+// downloadArtifact downloads the artifact from downloadURL and verifies its checksum.
+func (b *buildAndPublish) downloadArtifact(downloadURL string, artifactInfo *api.ArtifactDetails) (string, error) {
+	artifactReader, err := b.getArtifactReader(downloadURL, artifactInfo)
 	if err != nil {
 		return "", err
 	}
@@ -197,6 +320,7 @@ func (b *buildAndPublish) getArtifact(artifactInfo *api.ArtifactDetails) (string
 		return "", err
 	}
 	if errors.Is(b.Ctx.Err(), context.Canceled) {
+		os.Remove(file)
 		return "", b.Ctx.Err()
 	}
 
@@ -206,18 +330,19 @@ func (b *buildAndPublish) getArtifact(artifactInfo *api.ArtifactDetails) (string
 	if artifactInfo.Checksum == "" {
 		artifactInfo.Checksum = checksum
 	} else if checksum != artifactInfo.Checksum {
+		os.Remove(file)
 		return "", fmt.Errorf("expected checksum %q but got %q", artifactInfo.Checksum, checksum)
 	}
 
 	return file, nil
 }
 
-func (b *buildAndPublish) getArtifactReader(artifactInfo *api.ArtifactDetails) (http.ReadCloserWithChecksum, error) {
+func (b *buildAndPublish) getArtifactReader(downloadURL string, artifactInfo *api.ArtifactDetails) (http.ReadCloserWithChecksum, error) {
 	var artifactReader http.ReadCloserWithChecksum
 	var err error
 	const retries = 3
 	for range retries {
-		artifactReader, err = b.Getter.GetWithChecksumAndContext(b.Ctx, artifactInfo.DownloadURL, artifactInfo.ChecksumHash)
+		artifactReader, err = b.Getter.GetWithChecksumAndContext(b.Ctx, downloadURL, artifactInfo.ChecksumHash)
 		if err == nil {
 			return artifactReader, nil
 		}
@@ -245,7 +370,12 @@ func (b *buildAndPublish) readArtifact(artifactReader http.ReadCloserWithChecksu
 		}
 	}
 
-	file, err := os.CreateTemp("", "containerdisks")
+	tempDir := ""
+	if b.Cache != nil {
+		// Allows adding the file to the cache without copying it
+		tempDir = b.Cache.TempDir()
+	}
+	file, err := os.CreateTemp(tempDir, "containerdisks")
 	if err != nil {
 		return "", err
 	}
@@ -269,19 +399,14 @@ func (b *buildAndPublish) readArtifact(artifactReader http.ReadCloserWithChecksu
 	return file.Name(), nil
 }
 
-func (b *buildAndPublish) buildImages(entry *common.Entry) ([]v1.Image, []string, error) {
+func (b *buildAndPublish) buildImages(plan *buildPlan) ([]v1.Image, []string, error) {
 	var images []v1.Image
 	var artifacts []string
 
-	for i := range entry.Artifacts {
-		metadata := entry.Artifacts[i].Metadata()
-		artifactInfo, err := entry.Artifacts[i].Inspect()
-		if err != nil {
-			return nil, nil, fmt.Errorf("error introspecting artifact %q: %v", metadata.Describe(), err)
-		}
-
-		b.Log.Infof("Rebuild needed, downloading %q ...", artifactInfo.DownloadURL)
-		file, err := b.getArtifact(artifactInfo)
+	for i, artifactInfo := range plan.Artifacts() {
+		metadata := plan.Metadata(i)
+		b.Log.Info("Rebuild needed, getting the artifact ...")
+		file, err := b.getArtifact(metadata, &artifactInfo)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -303,19 +428,9 @@ func (b *buildAndPublish) buildImages(entry *common.Entry) ([]v1.Image, []string
 	return images, artifacts, nil
 }
 
-func (b *buildAndPublish) rebuildNeeded(entry *common.Entry) (bool, error) {
-	if len(entry.Artifacts) == 0 {
-		err := errors.New("entry has no artifacts to check for rebuild")
-		b.Log.Error(err)
-		return false, err
-	}
-
-	for i := range entry.Artifacts {
-		metadata := entry.Artifacts[i].Metadata()
-		artifactInfo, err := entry.Artifacts[i].Inspect()
-		if err != nil {
-			return false, fmt.Errorf("error introspecting artifact %q: %v", metadata.Describe(), err)
-		}
+func (b *buildAndPublish) rebuildNeeded(plan *buildPlan) (bool, error) {
+	for i, artifactInfo := range plan.Artifacts() {
+		metadata := plan.Metadata(i)
 		imageChecksum, err := b.getImageChecksum(metadata.Describe(), artifactInfo.ImageArchitecture)
 		if err != nil {
 			return false, err
@@ -331,7 +446,7 @@ func (b *buildAndPublish) rebuildNeeded(entry *common.Entry) (bool, error) {
 func (b *buildAndPublish) pushImage(containerDisk v1.Image, name string) error {
 	if !b.Options.DryRun {
 		b.Log.Infof("Pushing %s", name)
-		if err := b.Repo.PushImage(b.Ctx, containerDisk, name); err != nil {
+		if err := b.Repo.PushImage(b.Ctx, containerDisk, name, b.Options.AllowInsecureRegistry); err != nil {
 			b.Log.WithError(err).Error("Failed to push image")
 			return err
 		}
@@ -345,7 +460,7 @@ func (b *buildAndPublish) pushImage(containerDisk v1.Image, name string) error {
 func (b *buildAndPublish) pushImageIndex(containerDiskIndex v1.ImageIndex, name string) error {
 	if !b.Options.DryRun {
 		b.Log.Infof("Pushing %s image index", name)
-		if err := b.Repo.PushImageIndex(b.Ctx, containerDiskIndex, name); err != nil {
+		if err := b.Repo.PushImageIndex(b.Ctx, containerDiskIndex, name, b.Options.AllowInsecureRegistry); err != nil {
 			b.Log.WithError(err).Error("Failed to push image image")
 			return err
 		}
@@ -356,27 +471,6 @@ func (b *buildAndPublish) pushImageIndex(containerDiskIndex v1.ImageIndex, name
 	return nil
 }
 
-func prepareTags(timestamp time.Time, registry string, entry *common.Entry, artifactDetails *api.ArtifactDetails) []string {
-	metadata := entry.Artifacts[0].Metadata()
-	imageName := path.Join(registry, metadata.Describe())
-
-	names := []string{fmt.Sprintf("%s-%s", imageName, timestamp.Format("0601021504"))}
-	for _, tag := range artifactDetails.AdditionalUniqueTags {
-		if tag == "" {
-			continue
-		}
-		names = append(names, fmt.Sprintf("%s:%s", path.Join(registry, metadata.Name), tag))
-	}
-	// the least specific tag is last
-	names = append(names, imageName)
-
-	if entry.UseForLatest {
-		names = append(names, fmt.Sprintf("%s:%s", path.Join(registry, metadata.Name), "latest"))
-	}
-
-	return names
-}
-
 func cleanupArtifacts(artifacts []string) {
 	for _, file := range artifacts {
 		os.Remove(file)
@@ -0,0 +1,189 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+
+	"kubevirt.io/containerdisks/artifacts/debian"
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+	"kubevirt.io/containerdisks/pkg/build"
+	"kubevirt.io/containerdisks/pkg/docs"
+	"kubevirt.io/containerdisks/pkg/http"
+	"kubevirt.io/containerdisks/pkg/repository"
+	"kubevirt.io/containerdisks/testutil"
+)
+
+// fixturesDir contains hand-written upstream fixtures, which are small enough to build containerdisks from.
+const fixturesDir = "testdata/fixtures"
+
+type fakeDownload struct {
+	io.Reader
+	checksum hash.Hash
+}
+
+func (f *fakeDownload) Close() error {
+	return nil
+}
+
+func (f *fakeDownload) Checksum() string {
+	return hex.EncodeToString(f.checksum.Sum(nil))
+}
+
+// fakeDownloadGetter serves content per URL and fails for all other URLs.
+type fakeDownloadGetter struct {
+	http.Getter
+	content   map[string]string
+	requested []string
+}
+
+func (f *fakeDownloadGetter) GetWithChecksumAndContext(_ context.Context, fileURL string, checksumHasher func() hash.Hash) (
+	http.ReadCloserWithChecksum, error,
+) {
+	f.requested = append(f.requested, fileURL)
+	content, ok := f.content[fileURL]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	checksum := checksumHasher()
+	return &fakeDownload{Reader: io.TeeReader(bytes.NewBufferString(content), checksum), checksum: checksum}, nil
+}
+
+// fakePushRepository records pushed images and reports all images as not yet existing.
+type fakePushRepository struct {
+	repository.Repository
+	pushed map[string]v1.Image
+}
+
+func (f *fakePushRepository) ImageMetadata(_, _ string, _ bool) (*repository.ImageInfo, error) {
+	return nil, &transport.Error{Errors: []transport.Diagnostic{{Code: transport.ManifestUnknownErrorCode}}}
+}
+
+func (f *fakePushRepository) PushImage(_ context.Context, img v1.Image, imgRef string, _ bool) error {
+	f.pushed[imgRef] = img
+	return nil
+}
+
+var _ = Describe("Push", func() {
+	It("overrideMirror should keep the path of the upstream URL", func() {
+		mirrorURL, err := overrideMirror("https://artifactory.example.com/fedora/",
+			"https://download.fedoraproject.org/pub/fedora/disk.qcow2")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mirrorURL).To(Equal("https://artifactory.example.com/fedora/pub/fedora/disk.qcow2"))
+	})
+
+	It("getArtifact should try all mirrors until the checksum matches", func() {
+		checksum := sha256.Sum256([]byte("disk"))
+		getter := &fakeDownloadGetter{
+			content: map[string]string{
+				"https://upstream.example.com/disk.img": "corrupted",
+				"https://mirror.example.com/disk.img":   "disk",
+			},
+		}
+		b := buildAndPublish{
+			Ctx: context.Background(),
+			Log: logrus.NewEntry(logrus.StandardLogger()),
+			Options: &common.Options{
+				PublishImagesOptions: common.PublishImageOptions{
+					MirrorOverrides: map[string]string{"fedora": "https://artifactory.example.com"},
+				},
+			},
+			Getter: getter,
+		}
+		artifactInfo := &api.ArtifactDetails{
+			Checksum:     hex.EncodeToString(checksum[:]),
+			ChecksumHash: sha256.New,
+			DownloadURL:  "https://upstream.example.com/disk.img",
+			MirrorURLs:   []string{"https://mirror.example.com/disk.img"},
+		}
+
+		file, err := b.getArtifact(&api.Metadata{Name: "fedora"}, artifactInfo)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.Remove, file)
+		Expect(os.ReadFile(file)).To(BeEquivalentTo("disk"))
+		Expect(getter.requested).To(HaveExactElements(
+			"https://artifactory.example.com/disk.img",
+			"https://artifactory.example.com/disk.img",
+			"https://artifactory.example.com/disk.img",
+			"https://upstream.example.com/disk.img",
+			"https://mirror.example.com/disk.img",
+		))
+	})
+
+	It("Do should build and push containerdisks from upstream fixtures", func() {
+		getter := testutil.NewFixtureGetter(fixturesDir)
+		repo := &fakePushRepository{pushed: map[string]v1.Image{}}
+		b := buildAndPublish{
+			Ctx: context.Background(),
+			Log: logrus.NewEntry(logrus.StandardLogger()),
+			Options: &common.Options{
+				PublishImagesOptions: common.PublishImageOptions{
+					SourceRegistry: "quay.io/containerdisks",
+					TargetRegistry: "registry.example.com/containerdisks",
+				},
+			},
+			Repo:   repo,
+			Getter: getter,
+		}
+		entry := &common.Entry{
+			Artifacts: []api.Artifact{
+				debian.New(getter, "11", "bullseye", "x86_64", &docs.UserData{Username: "debian"}, nil),
+			},
+			UseForLatest: true,
+		}
+
+		tags, err := b.Do(entry, time.Date(2025, 10, 19, 12, 0, 0, 0, time.UTC))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tags).To(Equal([]string{"debian:11-2510191200", "debian:11-20251019-0000", "debian:11", "debian:latest"}))
+		Expect(repo.pushed).To(HaveLen(len(tags)))
+
+		img := repo.pushed["registry.example.com/containerdisks/debian:11"]
+		Expect(img).ToNot(BeNil())
+		config, err := img.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Architecture).To(Equal("amd64"))
+		checksum := sha512.Sum512([]byte("fixture disk\n"))
+		Expect(config.Config.Labels).To(HaveKeyWithValue(build.LabelShaSum, hex.EncodeToString(checksum[:])))
+	})
+
+	It("getArtifact should download from a mirror override over HTTP", func() {
+		server := testutil.NewFixtureServer(fixturesDir, "cloud.debian.org")
+		DeferCleanup(server.Close)
+
+		checksum := sha512.Sum512([]byte("fixture disk\n"))
+		b := buildAndPublish{
+			Ctx: context.Background(),
+			Log: logrus.NewEntry(logrus.StandardLogger()),
+			Options: &common.Options{
+				PublishImagesOptions: common.PublishImageOptions{
+					MirrorOverrides: map[string]string{"debian": server.URL},
+				},
+			},
+			Getter: &http.HTTPGetter{DefaultConnections: 2},
+		}
+		artifactInfo := &api.ArtifactDetails{
+			Checksum:     hex.EncodeToString(checksum[:]),
+			ChecksumHash: sha512.New,
+			DownloadURL:  "https://cloud.debian.org/images/cloud/bullseye/latest/debian-11-genericcloud-amd64.qcow2",
+		}
+
+		file, err := b.getArtifact(&api.Metadata{Name: "debian"}, artifactInfo)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.Remove, file)
+		Expect(os.ReadFile(file)).To(BeEquivalentTo("fixture disk\n"))
+	})
+})
@@ -0,0 +1,67 @@
+package images
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"kubevirt.io/containerdisks/cmd/medius/common"
+)
+
+func NewReportImagesCommand(options *common.Options) *cobra.Command {
+	options.ReportOptions = common.ReportOptions{
+		RegressionThreshold: defaultRegressionThreshold,
+	}
+
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Compare the boot metrics of verified containerdisks with a previous run",
+		Run: func(cmd *cobra.Command, args []string) {
+			results, err := readResultsFile(options.ImagesOptions.ResultsFile)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			previousResults, err := readResultsFile(options.ReportOptions.PreviousResultsFile)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			comparisons := compareResults(previousResults, results, options.ReportOptions.RegressionThreshold)
+			if err := writeReport(cmd.OutOrStdout(), comparisons); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	reportCmd.Flags().StringVar(&options.ReportOptions.PreviousResultsFile, "previous-results-file",
+		options.ReportOptions.PreviousResultsFile, "Results file of the previous run to compare with")
+	reportCmd.Flags().IntVar(&options.ReportOptions.RegressionThreshold, "regression-threshold",
+		options.ReportOptions.RegressionThreshold, "Increase of a boot metric in percent which is reported as regression")
+
+	err := reportCmd.MarkFlagRequired("previous-results-file")
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	return reportCmd
+}
+
+// writeReport writes the comparisons as table, regressions are marked.
+func writeReport(out io.Writer, comparisons []bootMetricComparison) error {
+	const padding = 2
+	w := tabwriter.NewWriter(out, 0, 0, padding, ' ', 0)
+	fmt.Fprintln(w, "CONTAINERDISK\tARCH\tMETRIC\tPREVIOUS\tCURRENT\tCHANGE\t")
+	for i := range comparisons {
+		c := &comparisons[i]
+		regression := ""
+		if c.Regressed {
+			regression = "REGRESSION"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%+.0f%%\t%s\n", c.Artifact, c.Arch, c.Metric.name,
+			c.Metric.format(c.Previous), c.Metric.format(c.Current), c.Change(), regression)
+	}
+
+	return w.Flush()
+}
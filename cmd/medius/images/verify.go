@@ -6,13 +6,17 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"maps"
 	"path"
+	"path/filepath"
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh"
+	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	urand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -25,20 +29,25 @@ import (
 	"kubevirt.io/containerdisks/pkg/api"
 	"kubevirt.io/containerdisks/pkg/architecture"
 	"kubevirt.io/containerdisks/pkg/docs"
+	"kubevirt.io/containerdisks/pkg/tests"
 )
 
 func NewVerifyImagesCommand(options *common.Options) *cobra.Command {
 	options.VerifyImagesOptions = common.VerifyImageOptions{
-		Namespace: "kubevirt",
-		Timeout:   600,
+		Namespace:           "kubevirt",
+		Timeout:             600,
+		BootSource:          BootSourceContainerDisk,
+		DataVolumeSize:      "20Gi",
+		RegressionThreshold: defaultRegressionThreshold,
+		Firmwares:           []string{ConfigurationDefault},
+		DiskBuses:           []string{ConfigurationDefault},
 	}
 
 	verifyCmd := &cobra.Command{
 		Use:   "verify",
 		Short: "Verify that containerdisks are bootable and guests are working",
 		Run: func(cmd *cobra.Command, args []string) {
-			results, err := readResultsFile(options.ImagesOptions.ResultsFile)
-			if err != nil {
+			if err := loadVerifyOptions(&options.VerifyImagesOptions); err != nil {
 				logrus.Fatal(err)
 			}
 
@@ -49,212 +58,494 @@ func NewVerifyImagesCommand(options *common.Options) *cobra.Command {
 				logrus.Fatal(err)
 			}
 
-			// Set target architecture
-			defineTargetArch(options, client)
+			// Set target architectures
+			defineTargetArchs(options, client)
 
-			focusMatched, resultsChan, workerErr := spawnWorkers(cmd.Context(), options, func(e *common.Entry) (*api.ArtifactResult, error) {
-				artifact, err := retrieveArchitectureArtifact(options, e)
-				if err != nil {
-					firstArtifactMedatada := e.Artifacts[0].Metadata()
-					logrus.Warn("Skipped " + firstArtifactMedatada.Name + ":" + firstArtifactMedatada.Version + " - " + err.Error())
-					return nil, nil
-				}
-				description := artifact.Metadata().Describe()
-				r, ok := results[description]
-				if !ok {
-					return nil, nil
-				}
-				if r.Err != "" {
-					return nil, fmt.Errorf("artifact %s failed in stage %s: %s", description, r.Stage, r.Err)
-				}
-				if r.Stage != StagePush {
-					return nil, nil
+			if options.VerifyImagesOptions.Image != "" {
+				if err = verifyImage(cmd.Context(), common.NewRegistry(&options.Upstream), options, client); err != nil {
+					logrus.Fatal(err)
 				}
+				return
+			}
 
-				errString := ""
-				err = verifyArtifact(cmd.Context(), artifact, r, options, client)
-				if err != nil {
-					errString = err.Error()
-				}
+			verifyResults(cmd.Context(), options, client)
+		},
+	}
+	addVerifyFlags(verifyCmd, &options.VerifyImagesOptions)
+	verifyCmd.Flags().AddGoFlagSet(kvirtcli.FlagSet())
+	verifyCmd.MarkFlagsMutuallyExclusive("registry", "image")
 
-				return &api.ArtifactResult{
-					Tags:  r.Tags,
-					Stage: StageVerify,
-					Err:   errString,
-				}, err
-			})
+	return verifyCmd
+}
 
-			for result := range resultsChan {
-				results[result.Key] = result.Value
-			}
+func addVerifyFlags(verifyCmd *cobra.Command, o *common.VerifyImageOptions) {
+	verifyCmd.Flags().StringVar(&o.Registry, "registry",
+		o.Registry, "Registry that contains containerdisks to verify")
+	verifyCmd.Flags().StringVar(&o.Image, "image",
+		o.Image, "Verify this containerdisk reference instead of the pushed containerdisks in the results file, "+
+			"e.g. quay.io/containerdisks/fedora:43")
+	verifyCmd.Flags().StringVar(&o.Artifact, "artifact",
+		o.Artifact, "Artifact whose VM, user data and tests are used to verify --image, e.g. fedora:43. "+
+			"Derived from the repository and tag of --image if empty")
+	verifyCmd.Flags().StringVar(&o.Namespace, "namespace",
+		o.Namespace, "Namespace to run verify in")
+	verifyCmd.Flags().BoolVar(&o.NoFail, "no-fail",
+		o.NoFail, "Return success even if a worker fails")
+	verifyCmd.Flags().IntVar(&o.Timeout, "timeout",
+		o.Timeout, "Maximum seconds to wait for VM to be running")
+	verifyCmd.Flags().StringSliceVar(&o.TargetArchitectures, "target-architecture",
+		o.TargetArchitectures,
+		"Target architectures for containerdisks verification, can be specified multiple times. Defaults to the architectures of all nodes")
+	verifyCmd.Flags().StringVar(&o.BootSource, "boot-source",
+		o.BootSource, fmt.Sprintf(
+			"Volume to boot VMs from: %q uses an ephemeral containerDisk, %q imports the containerdisk into a DataVolume "+
+				"bigger than the disk and checks that the root filesystem grew", BootSourceContainerDisk, BootSourceDataVolume))
+	verifyCmd.Flags().StringVar(&o.DataVolumeSize, "datavolume-size",
+		o.DataVolumeSize,
+		"Size of the DataVolumes to boot from, has to be much bigger than the disks of all containerdisks")
+	verifyCmd.Flags().StringVar(&o.StorageClass, "storage-class",
+		o.StorageClass, "Storage class of the DataVolumes to boot from, the default storage class is used if empty")
+	verifyCmd.Flags().StringVar(&o.ArtifactsDir, "artifacts-dir",
+		o.ArtifactsDir, "Directory to store the serial console log, VMI status, events, virt-launcher logs "+
+			"and guest agent info of VMs which failed verification in, one subdirectory per VM referenced from the results file")
+	verifyCmd.Flags().StringVar(&o.PreviousResultsFile, "previous-results-file",
+		o.PreviousResultsFile,
+		"Results file of a previous run, containerdisks whose boot metrics regressed compared to it fail")
+	verifyCmd.Flags().IntVar(&o.RegressionThreshold, "regression-threshold",
+		o.RegressionThreshold,
+		"Increase of a boot metric in percent compared to --previous-results-file which is a regression")
+	verifyCmd.Flags().StringSliceVar(&o.Firmwares, "matrix-firmware",
+		o.Firmwares, fmt.Sprintf("Firmwares to additionally verify VMs with, one of %v", firmwares))
+	verifyCmd.Flags().StringSliceVar(&o.DiskBuses, "matrix-disk-bus",
+		o.DiskBuses, fmt.Sprintf("Disk buses to additionally verify VMs with, one of %v", diskBuses))
+	verifyCmd.Flags().BoolVar(&o.Instancetype, "matrix-instancetype",
+		o.Instancetype, "Additionally verify VMs with the default instancetype and preference of the containerdisks")
+	verifyCmd.Flags().StringSliceVar(&o.TestSpecFiles, "test-spec",
+		o.TestSpecFiles, "YAML file with declarative guest tests to run over SSH in addition to the artifact tests, "+
+			"can be specified multiple times")
+}
 
-			if !focusMatched {
-				logrus.Fatalf("no artifact was processed, focus '%s' did not match", options.Focus)
-			}
+// loadVerifyOptions validates the options and loads the declarative tests.
+func loadVerifyOptions(o *common.VerifyImageOptions) error {
+	if (o.Registry == "") == (o.Image == "") {
+		return errors.New("exactly one of --registry and --image has to be set")
+	}
+	if _, err := dataVolumeSize(o); err != nil {
+		return err
+	}
+	if _, err := vmConfigurations(o); err != nil {
+		return err
+	}
+	for _, fileName := range o.TestSpecFiles {
+		specs, err := tests.LoadSpecs(fileName)
+		if err != nil {
+			return err
+		}
+		o.TestSpecs = append(o.TestSpecs, specs...)
+	}
 
-			if err := writeResultsFile(options.ImagesOptions.ResultsFile, results); err != nil {
-				logrus.Fatal(err)
-			}
+	return nil
+}
 
-			if workerErr != nil {
-				if options.VerifyImagesOptions.NoFail {
-					logrus.Warn(workerErr)
-				} else {
-					logrus.Fatal(workerErr)
-				}
-			}
-		},
+// verifyResults verifies the pushed containerdisks of the results file and records the results in it.
+func verifyResults(ctx context.Context, options *common.Options, client kvirtcli.KubevirtClient) {
+	results, err := readResultsFile(options.ImagesOptions.ResultsFile)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	var previousResults map[string]api.ArtifactResult
+	if options.VerifyImagesOptions.PreviousResultsFile != "" {
+		if previousResults, err = readResultsFile(options.VerifyImagesOptions.PreviousResultsFile); err != nil {
+			logrus.Fatal(err)
+		}
 	}
-	verifyCmd.Flags().StringVar(&options.VerifyImagesOptions.Registry, "registry",
-		options.VerifyImagesOptions.Registry, "Registry that contains containerdisks to verify")
-	verifyCmd.Flags().StringVar(&options.VerifyImagesOptions.Namespace, "namespace",
-		options.VerifyImagesOptions.Namespace, "Namespace to run verify in")
-	verifyCmd.Flags().BoolVar(&options.VerifyImagesOptions.NoFail, "no-fail",
-		options.VerifyImagesOptions.NoFail, "Return success even if a worker fails")
-	verifyCmd.Flags().IntVar(&options.VerifyImagesOptions.Timeout, "timeout",
-		options.VerifyImagesOptions.Timeout, "Maximum seconds to wait for VM to be running")
-	verifyCmd.Flags().StringVar(&options.VerifyImagesOptions.TargetArchitecture, "target-architecture",
-		options.VerifyImagesOptions.TargetArchitecture, "Target architecture for containerdisks verification")
-	verifyCmd.Flags().AddGoFlagSet(kvirtcli.FlagSet())
 
-	err := verifyCmd.MarkFlagRequired("registry")
-	if err != nil {
+	focusMatched, resultsChan, workerErr := spawnWorkers(ctx, options, func(e *common.Entry) (*api.ArtifactResult, error) {
+		return verifyEntry(ctx, e, results, previousResults, options, client)
+	})
+
+	for result := range resultsChan {
+		results[result.Key] = result.Value
+	}
+
+	if !focusMatched {
+		logrus.Fatalf("no artifact was processed, focus '%s' did not match", options.Focus)
+	}
+
+	if err := writeResultsFile(options.ImagesOptions.ResultsFile, results); err != nil {
 		logrus.Fatal(err)
 	}
 
-	return verifyCmd
+	if workerErr != nil {
+		if options.VerifyImagesOptions.NoFail {
+			logrus.Warn(workerErr)
+		} else {
+			logrus.Fatal(workerErr)
+		}
+	}
 }
 
-func defineTargetArch(options *common.Options, client kvirtcli.KubevirtClient) {
-	if options.VerifyImagesOptions.TargetArchitecture != "" {
-		return
+// verifyEntry verifies the containerdisk of the entry on all target architectures it was not verified on yet.
+// If previous results are given, boot metrics which regressed compared to them fail the verification.
+func verifyEntry(ctx context.Context, e *common.Entry, results, previousResults map[string]api.ArtifactResult,
+	options *common.Options, client kvirtcli.KubevirtClient,
+) (*api.ArtifactResult, error) {
+	artifacts := retrieveArchitectureArtifacts(options, e)
+	if len(artifacts) == 0 {
+		firstArtifactMedatada := e.Artifacts[0].Metadata()
+		logrus.Warnf("Skipped %s:%s - no artifact found for target architectures %v",
+			firstArtifactMedatada.Name, firstArtifactMedatada.Version, options.VerifyImagesOptions.TargetArchitectures)
+		return nil, nil
+	}
+	description := artifacts[0].Metadata().Describe()
+	r, ok := results[description]
+	if !ok {
+		return nil, nil
+	}
+	// Containerdisks which failed verification on some architectures are verified on them again
+	if r.Err != "" && (r.Stage != StageVerify || len(r.Architectures) == 0) {
+		return nil, fmt.Errorf("artifact %s failed in stage %s: %s", description, r.Stage, r.Err)
+	}
+	if r.Stage != StagePush && r.Stage != StageVerify {
+		return nil, nil
+	}
+	// Containerdisks which were already verified on some architectures are verified on the remaining ones
+	artifacts = pendingArchitectureArtifacts(artifacts, &r)
+	if len(artifacts) == 0 {
+		return nil, nil
+	}
+
+	errString := ""
+	architectures, err := verifyArchitectures(ctx, artifacts, r, options, client)
+	err = errors.Join(err, remainingFailures(architectures, artifacts))
+	if previousResults != nil {
+		err = errors.Join(err, failBootRegressions(description, previousResults[description], architectures,
+			options.VerifyImagesOptions.RegressionThreshold))
 	}
-	logrus.Info("Target architecture not specified, retrieving node architecture")
-	nodeArchitecture, err := retrieveNodeArch(client)
 	if err != nil {
-		logrus.Fatal(err)
+		errString = err.Error()
 	}
-	logrus.Infof("Node Architecture: %s\n", nodeArchitecture)
-	options.VerifyImagesOptions.TargetArchitecture = nodeArchitecture
+
+	return &api.ArtifactResult{
+		Tags:          r.Tags,
+		Architectures: architectures,
+		Stage:         StageVerify,
+		Err:           errString,
+	}, err
 }
 
-func retrieveNodeArch(client kvirtcli.KubevirtClient) (string, error) {
+func defineTargetArchs(options *common.Options, client kvirtcli.KubevirtClient) {
+	if len(options.VerifyImagesOptions.TargetArchitectures) > 0 {
+		return
+	}
+	logrus.Info("Target architectures not specified, retrieving node architectures")
 	nodes, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		return "", err
+		logrus.Fatal(err)
+	}
+	nodeArchitectures := retrieveNodeArchs(nodes.Items)
+	if len(nodeArchitectures) == 0 {
+		logrus.Fatal("no schedulable nodes found")
+	}
+	logrus.Infof("Node architectures: %v", nodeArchitectures)
+	options.VerifyImagesOptions.TargetArchitectures = nodeArchitectures
+}
+
+// retrieveNodeArchs returns the sorted architectures of all schedulable nodes.
+func retrieveNodeArchs(nodes []k8sv1.Node) []string {
+	var architectures []string
+	for i := range nodes {
+		if nodes[i].Spec.Unschedulable {
+			continue
+		}
+		architectures = append(architectures, nodes[i].Status.NodeInfo.Architecture)
 	}
-	if len(nodes.Items) == 0 {
-		return "", fmt.Errorf("no nodes found")
+	slices.Sort(architectures)
+
+	return slices.Compact(architectures)
+}
+
+// retrieveArchitectureArtifacts returns the artifacts of the entry for all target architectures.
+func retrieveArchitectureArtifacts(options *common.Options, e *common.Entry) []api.Artifact {
+	var artifacts []api.Artifact
+	for _, a := range e.Artifacts {
+		if slices.Contains(options.VerifyImagesOptions.TargetArchitectures, architecture.GetImageArchitecture(a.Metadata().Arch)) {
+			artifacts = append(artifacts, a)
+		}
 	}
-	return nodes.Items[0].Status.NodeInfo.Architecture, nil
+
+	return artifacts
 }
 
-func retrieveArchitectureArtifact(options *common.Options, e *common.Entry) (api.Artifact, error) {
-	targetArchitecture := options.VerifyImagesOptions.TargetArchitecture
-	archIndex := slices.IndexFunc(e.Artifacts, func(a api.Artifact) bool {
-		return architecture.GetImageArchitecture(a.Metadata().Arch) == targetArchitecture
+// pendingArchitectureArtifacts returns the artifacts whose architecture was not verified yet, architectures
+// which failed before are verified again.
+func pendingArchitectureArtifacts(artifacts []api.Artifact, res *api.ArtifactResult) []api.Artifact {
+	return slices.DeleteFunc(slices.Clone(artifacts), func(a api.Artifact) bool {
+		return res.Architectures[architecture.GetImageArchitecture(a.Metadata().Arch)].Verified
 	})
-	if archIndex == -1 {
-		return nil, fmt.Errorf("no artifact found for target architecture %s", targetArchitecture)
+}
+
+// remainingFailures returns the errors of the architectures which failed before and were not verified again.
+func remainingFailures(architectures map[string]api.ArchitectureResult, verified []api.Artifact) error {
+	var errs []error
+	for _, arch := range slices.Sorted(maps.Keys(architectures)) {
+		result := architectures[arch]
+		if result.Verified || slices.ContainsFunc(verified, func(a api.Artifact) bool {
+			return architecture.GetImageArchitecture(a.Metadata().Arch) == arch
+		}) {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", arch, result.Err))
 	}
-	return e.Artifacts[archIndex], nil
+
+	return errors.Join(errs...)
 }
 
-func verifyArtifact(ctx context.Context, a api.Artifact, res api.ArtifactResult, o *common.Options, client kvirtcli.KubevirtClient) error {
+// verifyArchitectures verifies the artifacts of all architectures in parallel, every VM is scheduled on a node of
+// its architecture. The results are merged into the architecture results of res.
+func verifyArchitectures(ctx context.Context, artifacts []api.Artifact, res api.ArtifactResult, o *common.Options,
+	client kvirtcli.KubevirtClient,
+) (map[string]api.ArchitectureResult, error) {
+	configurations, err := vmConfigurations(&o.VerifyImagesOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]api.ArchitectureResult, len(artifacts))
+	errs := make([]error, len(artifacts))
+	wg := &sync.WaitGroup{}
+	for i, artifact := range artifacts {
+		wg.Go(func() {
+			results[i], errs[i] = verifyArtifactConfigurations(ctx, artifact, res, o, client, configurations)
+		})
+	}
+	wg.Wait()
+
+	architectures := maps.Clone(res.Architectures)
+	if architectures == nil {
+		architectures = map[string]api.ArchitectureResult{}
+	}
+	for i, artifact := range artifacts {
+		arch := architecture.GetImageArchitecture(artifact.Metadata().Arch)
+		result := results[i]
+		result.Verified = errs[i] == nil
+		if errs[i] != nil {
+			result.Err = errs[i].Error()
+			errs[i] = fmt.Errorf("%s: %w", arch, errs[i])
+		}
+		architectures[arch] = result
+	}
+
+	return architectures, errors.Join(errs...)
+}
+
+// verifyArtifactConfigurations verifies the VM of the artifact and then all additional configurations of it one
+// after another. Only the VM of the artifact decides whether the containerdisk is verified, the results of the
+// additional configurations show which configurations boot.
+func verifyArtifactConfigurations(ctx context.Context, a api.Artifact, res api.ArtifactResult, o *common.Options,
+	client kvirtcli.KubevirtClient, configurations []vmConfiguration,
+) (api.ArchitectureResult, error) {
+	result, err := verifyArtifact(ctx, a, res, o, client, defaultVMConfiguration)
+
+	for _, c := range artifactVMConfigurations(configurations, a.Metadata()) {
+		if c == defaultVMConfiguration {
+			continue
+		}
+		if errors.Is(ctx.Err(), context.Canceled) {
+			break
+		}
+
+		configResult, configErr := verifyArtifact(ctx, a, res, o, client, c)
+		r := api.ConfigurationResult{Verified: configErr == nil, Diagnostics: configResult.Diagnostics}
+		if configErr != nil {
+			r.Err = configErr.Error()
+		}
+		if result.Configurations == nil {
+			result.Configurations = map[string]api.ConfigurationResult{}
+		}
+		result.Configurations[c.String()] = r
+	}
+
+	return result, err
+}
+
+// verifyArtifact boots a VM with the given configuration from the containerdisk of the artifact and runs the
+// artifact tests on it. The returned result contains the measured boot metrics. If it fails and an artifacts
+// directory is configured, diagnostics of the VM are collected before it is deleted and the directory they were
+// stored in is returned in the result.
+func verifyArtifact(ctx context.Context, a api.Artifact, res api.ArtifactResult, o *common.Options,
+	client kvirtcli.KubevirtClient, config vmConfiguration,
+) (result api.ArchitectureResult, err error) {
 	log := common.Logger(a)
+	if config != defaultVMConfiguration {
+		log = log.WithField("configuration", config.String())
+	}
 
 	if len(res.Tags) == 0 {
-		err := errors.New("no containerdisks to verify")
+		err = errors.New("no containerdisks to verify")
 		log.Error(err)
-		return err
+		return result, err
 	}
 
 	imgRef := path.Join(o.VerifyImagesOptions.Registry, res.Tags[0])
-	vm, username, privateKey, err := createVM(a, imgRef)
+	vm, params, testFns, err := prepareVM(a, imgRef, &o.VerifyImagesOptions, config)
 	if err != nil {
 		log.WithError(err).Error("Failed to create VM object")
-		return err
+		return result, err
 	}
 	if errors.Is(ctx.Err(), context.Canceled) {
-		return ctx.Err()
+		return result, ctx.Err()
 	}
 
 	vmClient := client.VirtualMachine(o.VerifyImagesOptions.Namespace)
 	log.Info("Creating VM")
+	created := time.Now()
 	if vm, err = vmClient.Create(ctx, vm, metav1.CreateOptions{}); err != nil {
 		log.WithError(err).Error("Failed to create VM")
-		return err
+		return result, err
 	}
 
 	defer func() {
-		if err = vmClient.Delete(ctx, vm.Name, metav1.DeleteOptions{GracePeriodSeconds: ptr.To[int64](0)}); err != nil {
-			log.WithError(err).Error("Failed to delete VM")
+		if err != nil && o.VerifyImagesOptions.ArtifactsDir != "" && !errors.Is(ctx.Err(), context.Canceled) {
+			result.Diagnostics = filepath.Join(o.VerifyImagesOptions.ArtifactsDir, vm.Name)
+			log.Infof("Collecting diagnostics in %s", result.Diagnostics)
+			if collectErr := collectDiagnostics(ctx, client, o.VerifyImagesOptions.Namespace, vm.Name, result.Diagnostics); collectErr != nil {
+				log.WithError(collectErr).Warn("Failed to collect some diagnostics")
+			}
+		}
+		if deleteErr := vmClient.Delete(ctx, vm.Name, metav1.DeleteOptions{GracePeriodSeconds: ptr.To[int64](0)}); deleteErr != nil {
+			log.WithError(deleteErr).Error("Failed to delete VM")
 		}
 	}()
 
 	if errors.Is(ctx.Err(), context.Canceled) {
-		return ctx.Err()
+		return result, ctx.Err()
+	}
+
+	result.BootMetrics, err = runTests(ctx, log, client, vm, params, testFns, o, created)
+	return result, err
+}
+
+// prepareVM returns the VM object of the artifact with the given configuration and the tests to run on it.
+func prepareVM(a api.Artifact, imgRef string, o *common.VerifyImageOptions, config vmConfiguration,
+) (*v1.VirtualMachine, *api.ArtifactTestParams, []api.ArtifactTest, error) {
+	vm, params, err := createVM(a, imgRef)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	config.apply(vm, a.Metadata())
+	testFns := artifactTests(a, o)
+
+	size, err := dataVolumeSize(o)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !size.IsZero() {
+		arch := architecture.GetImageArchitecture(a.Metadata().Arch)
+		if err = withDataVolume(vm, imgRef, arch, size, o.StorageClass); err != nil {
+			return nil, nil, nil, err
+		}
+		testFns = append(testFns, tests.RootFilesystemGrown(size.Value()))
 	}
 
+	return vm, params, testFns, nil
+}
+
+// runTests waits for the VM to be ready and runs the tests on its VMI. The returned boot metrics are measured
+// relative to created, they are nil if the VM did not become ready.
+func runTests(ctx context.Context, log *logrus.Entry, client kvirtcli.KubevirtClient, vm *v1.VirtualMachine,
+	params *api.ArtifactTestParams, testFns []api.ArtifactTest, o *common.Options, created time.Time,
+) (*api.BootMetrics, error) {
 	log.Info("Waiting for VM to be ready")
-	if err = waitVMReady(ctx, vm.Name, vmClient, o.VerifyImagesOptions.Timeout); err != nil {
+	vmClient := client.VirtualMachine(o.VerifyImagesOptions.Namespace)
+	if err := waitVMReady(ctx, vm.Name, vmClient, o.VerifyImagesOptions.Timeout); err != nil {
 		if errors.Is(ctx.Err(), context.Canceled) {
-			return ctx.Err()
+			return nil, ctx.Err()
 		}
 
 		log.WithError(err).Error("VM not ready")
-		return err
+		return nil, err
 	}
+	metrics := &api.BootMetrics{Ready: secondsSince(created)}
+	stopMeasuring := measureGuest(ctx, client, vm, params, created, log)
+	defer stopMeasuring(metrics)
 
 	vmi, err := client.VirtualMachineInstance(o.VerifyImagesOptions.Namespace).Get(ctx, vm.Name, metav1.GetOptions{})
 	if err != nil {
 		log.WithError(err).Error("Failed to get VMI")
-		return err
+		return metrics, err
 	}
 	if errors.Is(ctx.Err(), context.Canceled) {
-		return ctx.Err()
+		return metrics, ctx.Err()
+	}
+
+	if params.Username != "" {
+		if sshErr := tests.WaitSSHReachable(ctx, vmi, params); sshErr != nil {
+			log.WithError(sshErr).Warn("Failed to measure when SSH was reachable")
+		} else {
+			metrics.SSHReachable = secondsSince(created)
+		}
 	}
 
 	log.Info("Running tests on VMI")
-	for _, testFn := range a.Tests() {
-		if err = testFn(ctx, vmi, &api.ArtifactTestParams{Username: username, PrivateKey: privateKey}); err != nil {
+	for _, testFn := range testFns {
+		if err = testFn(ctx, vmi, params); err != nil {
 			log.WithError(err).Error("Failed to verify containerdisk")
-			return err
+			return metrics, err
 		}
 		if errors.Is(ctx.Err(), context.Canceled) {
-			return ctx.Err()
+			return metrics, ctx.Err()
 		}
 	}
 
 	log.Info("Tests successful")
-	return nil
+	return metrics, nil
+}
+
+// artifactTests returns the tests of the artifact followed by the declarative tests which select it.
+func artifactTests(a api.Artifact, o *common.VerifyImageOptions) []api.ArtifactTest {
+	testFns := slices.Clone(a.Tests())
+	for i := range o.TestSpecs {
+		if spec := &o.TestSpecs[i]; spec.Selects(a.Metadata().Describe()) {
+			testFns = append(testFns, spec.Test())
+		}
+	}
+
+	return testFns
 }
 
-func createVM(artifact api.Artifact, imgRef string) (*v1.VirtualMachine, string, ed25519.PrivateKey, error) {
+func createVM(artifact api.Artifact, imgRef string) (*v1.VirtualMachine, *api.ArtifactTestParams, error) {
 	metadata := artifact.Metadata()
-	username := metadata.ExampleUserData.Username
 
 	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
-		return nil, "", nil, err
+		return nil, nil, err
 	}
 
 	publicKey, err := marshallPublicKey(&privateKey)
 	if err != nil {
-		return nil, "", nil, err
+		return nil, nil, err
 	}
 
-	userData := artifact.UserData(
-		&docs.UserData{
-			Username:       username,
-			AuthorizedKeys: []string{publicKey},
-		},
-	)
-
 	name := randName(metadata.Name)
-	vm := artifact.VM(name, imgRef, userData)
+	userData := docs.UserData{
+		Username:       metadata.ExampleUserData.Username,
+		AuthorizedKeys: []string{publicKey},
+		Hostname:       name,
+	}
+
+	vm := artifact.VM(name, imgRef, artifact.UserData(&userData))
 	vm.Spec.Template.Spec.TerminationGracePeriodSeconds = ptr.To[int64](0)
-	return vm, username, privateKey, nil
+	if vm.Spec.Template.Spec.NodeSelector == nil {
+		vm.Spec.Template.Spec.NodeSelector = map[string]string{}
+	}
+	vm.Spec.Template.Spec.NodeSelector[k8sv1.LabelArchStable] = architecture.GetImageArchitecture(metadata.Arch)
+
+	return vm, &api.ArtifactTestParams{
+		Username:   userData.Username,
+		PrivateKey: privateKey,
+		UserData:   userData,
+	}, nil
 }
 
 func marshallPublicKey(key *ed25519.PrivateKey) (string, error) {
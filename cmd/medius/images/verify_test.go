@@ -0,0 +1,116 @@
+package images
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+
+	"kubevirt.io/containerdisks/artifacts/generic"
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+func archNode(arch string, unschedulable bool) k8sv1.Node {
+	return k8sv1.Node{
+		Spec:   k8sv1.NodeSpec{Unschedulable: unschedulable},
+		Status: k8sv1.NodeStatus{NodeInfo: k8sv1.NodeSystemInfo{Architecture: arch}},
+	}
+}
+
+func archArtifact(arch string) api.Artifact {
+	return generic.New(&api.ArtifactDetails{}, &api.Metadata{Name: "fedora", Version: "43", Arch: arch})
+}
+
+func artifactArchs(artifacts []api.Artifact) []string {
+	var archs []string
+	for _, a := range artifacts {
+		archs = append(archs, a.Metadata().Arch)
+	}
+	return archs
+}
+
+var _ = Describe("Verify", func() {
+	entry := &common.Entry{
+		Artifacts: []api.Artifact{archArtifact("x86_64"), archArtifact("aarch64"), archArtifact("s390x")},
+	}
+
+	It("retrieveNodeArchs should return the architectures of all schedulable nodes once", func() {
+		nodes := []k8sv1.Node{
+			archNode("arm64", false),
+			archNode("amd64", false),
+			archNode("arm64", false),
+			archNode("s390x", true),
+		}
+		Expect(retrieveNodeArchs(nodes)).To(Equal([]string{"amd64", "arm64"}))
+	})
+
+	It("retrieveArchitectureArtifacts should return the artifacts of all target architectures", func() {
+		options := &common.Options{
+			VerifyImagesOptions: common.VerifyImageOptions{TargetArchitectures: []string{"s390x", "amd64", "ppc64le"}},
+		}
+		Expect(artifactArchs(retrieveArchitectureArtifacts(options, entry))).To(Equal([]string{"x86_64", "s390x"}))
+	})
+
+	It("pendingArchitectureArtifacts should skip already verified architectures", func() {
+		res := &api.ArtifactResult{
+			Stage: StageVerify,
+			Architectures: map[string]api.ArchitectureResult{
+				"amd64": {Verified: true},
+				"arm64": {Verified: false, Err: "boot timeout"},
+			},
+		}
+		Expect(artifactArchs(pendingArchitectureArtifacts(entry.Artifacts, res))).To(Equal([]string{"aarch64", "s390x"}))
+		Expect(entry.Artifacts).To(HaveLen(3))
+	})
+
+	It("verifyEntry should verify the architectures which failed before again", func() {
+		options := &common.Options{
+			VerifyImagesOptions: common.VerifyImageOptions{TargetArchitectures: []string{"amd64", "arm64"}},
+		}
+		// Without tags the verification fails before a VM is created
+		results := map[string]api.ArtifactResult{
+			"fedora:43": {
+				Stage: StageVerify,
+				Err:   "arm64: boot timeout",
+				Architectures: map[string]api.ArchitectureResult{
+					"amd64": {Verified: true},
+					"arm64": {Verified: false, Err: "boot timeout"},
+				},
+			},
+		}
+
+		result, err := verifyEntry(context.Background(), entry, results, nil, options, nil)
+		Expect(err).To(MatchError("arm64: no containerdisks to verify"))
+		Expect(result.Stage).To(Equal(StageVerify))
+		Expect(result.Err).To(Equal("arm64: no containerdisks to verify"))
+		Expect(result.Architectures).To(Equal(map[string]api.ArchitectureResult{
+			"amd64": {Verified: true},
+			"arm64": {Verified: false, Err: "no containerdisks to verify"},
+		}))
+	})
+
+	It("remainingFailures should only report failed architectures which were not verified again", func() {
+		architectures := map[string]api.ArchitectureResult{
+			"amd64": {Verified: true},
+			"arm64": {Verified: false, Err: "boot timeout"},
+			"s390x": {Verified: false, Err: "ssh failed"},
+		}
+		Expect(remainingFailures(architectures, []api.Artifact{archArtifact("aarch64")})).To(MatchError("s390x: ssh failed"))
+		Expect(remainingFailures(architectures, []api.Artifact{archArtifact("aarch64"), archArtifact("s390x")})).To(Succeed())
+	})
+
+	It("createVM should schedule the VM on a node of the artifact architecture", func() {
+		vm, _, err := createVM(archArtifact("aarch64"), "registry:5000/fedora:43")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(vm.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue(k8sv1.LabelArchStable, "arm64"))
+	})
+
+	It("createVM should provision the VM with its name as hostname", func() {
+		vm, params, err := createVM(archArtifact("x86_64"), "registry:5000/fedora:43")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(params.UserData.Hostname).To(Equal(vm.Name))
+		Expect(params.UserData.AuthorizedKeys).To(HaveLen(1))
+	})
+})
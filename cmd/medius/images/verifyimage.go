@@ -0,0 +1,80 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+
+	crname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/sirupsen/logrus"
+	kvirtcli "kubevirt.io/client-go/kubecli"
+
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+// verifyImage verifies the containerdisk reference of the image option with the VM, user data and tests of its
+// artifact on all target architectures. It allows re-checking published containerdisks, nothing is written to the
+// results file.
+func verifyImage(ctx context.Context, registry []common.Entry, options *common.Options, client kvirtcli.KubevirtClient) error {
+	image := options.VerifyImagesOptions.Image
+	description := options.VerifyImagesOptions.Artifact
+	if description == "" {
+		var err error
+		if description, err = imageArtifactDescription(image); err != nil {
+			return err
+		}
+	}
+
+	entry, err := findEntry(registry, description)
+	if err != nil {
+		return err
+	}
+	artifacts := retrieveArchitectureArtifacts(options, entry)
+	if len(artifacts) == 0 {
+		return fmt.Errorf("artifact %s has no artifact for target architectures %v",
+			description, options.VerifyImagesOptions.TargetArchitectures)
+	}
+
+	log := logrus.WithField("image", image)
+	log.Infof("Verifying with artifact %s", description)
+	architectures, err := verifyArchitectures(ctx, artifacts, api.ArtifactResult{Tags: []string{image}}, options, client)
+	for _, arch := range slices.Sorted(maps.Keys(architectures)) {
+		log.WithField("arch", arch).Infof("Verified: %t", architectures[arch].Verified)
+	}
+
+	return err
+}
+
+// imageArtifactDescription derives the artifact of a containerdisk reference from its repository and tag,
+// e.g. "fedora:43" from "quay.io/containerdisks/fedora:43" or "quay.io/containerdisks/fedora:43-2510191200".
+func imageArtifactDescription(image string) (string, error) {
+	ref, err := crname.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	tag, ok := ref.(crname.Tag)
+	if !ok {
+		return "", fmt.Errorf("cannot derive the artifact of %q without a tag, use --artifact to select one", image)
+	}
+
+	version := tag.TagStr()
+	if matches := timestampTagRegExp.FindStringSubmatch(version); matches != nil {
+		version = matches[1]
+	}
+
+	return path.Base(ref.Context().RepositoryStr()) + ":" + version, nil
+}
+
+// findEntry returns the entry whose artifacts are described by description, e.g. "fedora:43".
+func findEntry(registry []common.Entry, description string) (*common.Entry, error) {
+	for i := range registry {
+		if len(registry[i].Artifacts) > 0 && registry[i].Artifacts[0].Metadata().Describe() == description {
+			return &registry[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no artifact %q found, use --artifact to select one", description)
+}
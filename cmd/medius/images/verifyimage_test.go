@@ -0,0 +1,54 @@
+package images
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+
+	"kubevirt.io/containerdisks/artifacts/generic"
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+var _ = Describe("Verify image", func() {
+	registry := []common.Entry{
+		{Artifacts: []api.Artifact{generic.New(&api.ArtifactDetails{}, &api.Metadata{Name: "fedora", Version: "42", Arch: "x86_64"})}},
+		{Artifacts: []api.Artifact{archArtifact("x86_64"), archArtifact("aarch64")}},
+	}
+
+	DescribeTable("imageArtifactDescription should derive the artifact from the image",
+		func(image string, description string, matchErr types.GomegaMatcher) {
+			d, err := imageArtifactDescription(image)
+			Expect(err).To(matchErr)
+			Expect(d).To(Equal(description))
+		},
+		Entry("moving tag", "quay.io/containerdisks/fedora:43", "fedora:43", Succeed()),
+		Entry("timestamped tag", "quay.io/containerdisks/fedora:43-2510191200", "fedora:43", Succeed()),
+		Entry("registry with port", "registry:5000/fedora:43", "fedora:43", Succeed()),
+		Entry("digest", "quay.io/containerdisks/fedora@sha256:"+strings.Repeat("a", 64), "",
+			MatchError(ContainSubstring("use --artifact to select one"))),
+	)
+
+	It("findEntry should return the entry of the artifact", func() {
+		entry, err := findEntry(registry, "fedora:43")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entry).To(BeIdenticalTo(&registry[1]))
+
+		_, err = findEntry(registry, "fedora:latest")
+		Expect(err).To(MatchError(`no artifact "fedora:latest" found, use --artifact to select one`))
+	})
+
+	It("verifyImage should fail without artifacts for the target architectures", func() {
+		options := &common.Options{
+			VerifyImagesOptions: common.VerifyImageOptions{
+				Image:               "quay.io/containerdisks/fedora:43",
+				TargetArchitectures: []string{"s390x"},
+			},
+		}
+		Expect(verifyImage(context.Background(), registry, options, nil)).To(
+			MatchError("artifact fedora:43 has no artifact for target architectures [s390x]"))
+	})
+})
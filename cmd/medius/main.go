@@ -7,10 +7,14 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"kubevirt.io/containerdisks/cmd/medius/common"
 	"kubevirt.io/containerdisks/cmd/medius/docs"
 	"kubevirt.io/containerdisks/cmd/medius/images"
+	"kubevirt.io/containerdisks/cmd/medius/upstream"
+	"kubevirt.io/containerdisks/pkg/http"
+	"kubevirt.io/containerdisks/pkg/network"
 )
 
 func main() {
@@ -26,6 +30,9 @@ func main() {
 		Use:   "medius",
 		Short: "medius determines if new OS images are released and publishes them as containerdisks",
 		Run:   func(cmd *cobra.Command, args []string) {},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return setup(options)
+		},
 	}
 
 	imagesCmd := &cobra.Command{
@@ -40,16 +47,32 @@ func main() {
 			os.Exit(1)
 		},
 	}
+	upstreamCmd := &cobra.Command{
+		Use: "upstream",
+		Run: func(cmd *cobra.Command, args []string) {
+			os.Exit(1)
+		},
+	}
 	rootCmd.AddCommand(imagesCmd)
 	rootCmd.AddCommand(docsCmd)
+	rootCmd.AddCommand(upstreamCmd)
 
 	imagesCmd.AddCommand(images.NewPromoteImagesCommand(options))
 	imagesCmd.AddCommand(images.NewPublishImagesCommand(options))
+	imagesCmd.AddCommand(images.NewPruneImagesCommand(options))
+	imagesCmd.AddCommand(images.NewReportImagesCommand(options))
 	imagesCmd.AddCommand(images.NewVerifyImagesCommand(options))
 	docsCmd.AddCommand(docs.NewPublishDocsCommand(options))
+	upstreamCmd.AddCommand(upstream.NewSyncUpstreamCommand(options))
 
 	rootCmd.PersistentFlags().BoolVar(&options.AllowInsecureRegistry, "insecure-skip-tls",
 		options.AllowInsecureRegistry, "allow connecting to insecure registries")
+	addConnectionFlags(rootCmd.PersistentFlags(), options)
+	rootCmd.PersistentFlags().StringVar(&options.MetadataCacheDir, "metadata-cache-dir",
+		options.MetadataCacheDir, "Directory to cache upstream metadata in, which is then only downloaded if it changed")
+	rootCmd.PersistentFlags().StringVar(&options.UpstreamMirror, "upstream-mirror",
+		options.UpstreamMirror,
+		"Directory to read upstream files from instead of the network, e.g. https://host/path is read from <dir>/host/path")
 	rootCmd.PersistentFlags().BoolVar(&options.DryRun, "dry-run",
 		options.DryRun, "don't publish anything")
 	rootCmd.PersistentFlags().StringVar(&options.Focus, "focus",
@@ -62,11 +85,59 @@ func main() {
 	ctx, cancel := getInterruptibleContext()
 	defer cancel()
 
-	if err := rootCmd.ExecuteContext(ctx); err != nil {
+	err := rootCmd.ExecuteContext(ctx)
+	if closeErr := options.Network.Close(); closeErr != nil {
+		logrus.Warnf("error cleaning up the network configuration: %v", closeErr)
+	}
+	if err != nil {
 		logrus.Fatal(err)
 	}
 }
 
+// setup configures the registry credentials, the network and the upstream access of all commands.
+func setup(options *common.Options) (err error) {
+	options.RegistryAuth, err = common.NewAuthConfig(&options.AuthOptions)
+	if err != nil {
+		return err
+	}
+	options.Network, err = network.New(&options.NetworkOptions)
+	if err != nil {
+		return err
+	}
+	// Commands exit with logrus.Fatal, which does not run deferred functions.
+	logrus.RegisterExitHandler(func() { _ = options.Network.Close() })
+	options.Upstream.Client = options.Network.Client()
+	if options.UpstreamMirror != "" {
+		options.Upstream.Mirror = &http.MirrorGetter{Dir: options.UpstreamMirror}
+	}
+	options.Upstream.Metadata, err = http.NewMetadataCache(options.MetadataCacheDir)
+	return err
+}
+
+// addConnectionFlags adds the registry credential and network flags.
+func addConnectionFlags(flags *pflag.FlagSet, options *common.Options) {
+	flags.StringVar(&options.AuthOptions.AuthFile, "authfile",
+		options.AuthOptions.AuthFile, "containers-auth.json(5) file with registry credentials")
+	flags.StringVar(&options.AuthOptions.Username, "username",
+		options.AuthOptions.Username, "Username for all registries without more specific credentials")
+	flags.StringVar(&options.AuthOptions.PasswordFile, "password-file",
+		options.AuthOptions.PasswordFile, "File containing the password of --username")
+	flags.StringToStringVar(&options.AuthOptions.RegistryCredentials, "registry-credentials",
+		options.AuthOptions.RegistryCredentials, "Credentials per registry or repository as <registry>=<username>:<password-file>")
+	flags.StringVar(&options.NetworkOptions.CABundle, "ca-bundle",
+		options.NetworkOptions.CABundle, "PEM file with CA certificates to trust in addition to the system CAs")
+	flags.StringVar(&options.NetworkOptions.ClientCert, "client-cert",
+		options.NetworkOptions.ClientCert, "PEM client certificate for mTLS to registries and upstream sources")
+	flags.StringVar(&options.NetworkOptions.ClientKey, "client-key",
+		options.NetworkOptions.ClientKey, "PEM key of --client-cert")
+	flags.StringVar(&options.NetworkOptions.HTTPProxy, "http-proxy",
+		options.NetworkOptions.HTTPProxy, "Proxy for HTTP requests, overrides HTTP_PROXY")
+	flags.StringVar(&options.NetworkOptions.HTTPSProxy, "https-proxy",
+		options.NetworkOptions.HTTPSProxy, "Proxy for HTTPS requests, overrides HTTPS_PROXY")
+	flags.StringVar(&options.NetworkOptions.NoProxy, "no-proxy",
+		options.NetworkOptions.NoProxy, "Comma separated hosts which are not proxied, overrides NO_PROXY")
+}
+
 func getInterruptibleContext() (ctx context.Context, cancel func()) {
 	ctx = context.Background()
 	ctx, cancelCtx := context.WithCancel(ctx)
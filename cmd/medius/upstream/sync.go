@@ -0,0 +1,145 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"kubevirt.io/containerdisks/cmd/medius/common"
+	"kubevirt.io/containerdisks/pkg/api"
+	"kubevirt.io/containerdisks/pkg/http"
+)
+
+func NewSyncUpstreamCommand(options *common.Options) *cobra.Command {
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Download all upstream metadata and images needed for the containerdisks into the upstream mirror",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.UpstreamMirror == "" {
+				return errors.New("--upstream-mirror has to be specified")
+			}
+			return run(cmd.Context(), options, &http.MirrorGetter{Dir: options.UpstreamMirror})
+		},
+	}
+
+	return syncCmd
+}
+
+func run(ctx context.Context, options *common.Options, mirror *http.MirrorGetter) error {
+	success := true
+	focusMatched := false
+
+	// Read from the network and record all metadata files in the mirror
+	upstream := options.Upstream
+	upstream.Mirror = mirror
+	upstream.RecordMirror = true
+	registry := common.NewRegistry(&upstream)
+	for i := range registry {
+		if common.ShouldSkip(options.Focus, &registry[i]) {
+			continue
+		}
+		focusMatched = true
+
+		for _, artifact := range registry[i].Artifacts {
+			log := common.Logger(artifact)
+			getter := upstream
+			getter.Log = log
+			if err := syncArtifact(ctx, log, &getter, mirror, artifact); err != nil {
+				if errors.Is(err, context.Canceled) {
+					return err
+				}
+				success = false
+				log.Error(err)
+			}
+		}
+	}
+
+	if !focusMatched {
+		return fmt.Errorf("no artifact was processed, focus '%s' did not match", options.Focus)
+	}
+
+	if !success {
+		return errors.New("an error occurred during syncing the upstream mirror")
+	}
+
+	return nil
+}
+
+// checksumsDir contains the checksums of the mirrored images in the same layout as the images,
+// upstream hosts never start with a dot.
+const checksumsDir = ".checksums"
+
+// syncArtifact mirrors the image of the artifact from its DownloadURL with getter. MirrorURLs are not synced,
+// the upstream mirror serves the image at its DownloadURL only.
+func syncArtifact(ctx context.Context, log *logrus.Entry, getter http.Getter, mirror *http.MirrorGetter,
+	artifact api.Artifact,
+) error {
+	details, err := artifact.Inspect()
+	if err != nil {
+		return fmt.Errorf("error introspecting artifact %q: %v", artifact.Metadata().Describe(), err)
+	}
+
+	checksums := &http.MirrorGetter{Dir: filepath.Join(mirror.Dir, checksumsDir)}
+	if details.Checksum != "" && mirroredChecksum(ctx, mirror, checksums, details) == details.Checksum {
+		log.Infof("%s is already mirrored", details.DownloadURL)
+		return nil
+	}
+
+	log.Infof("Mirroring %s ...", details.DownloadURL)
+	reader, err := getter.GetWithChecksumAndContext(ctx, details.DownloadURL, details.ChecksumHash)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	err = mirror.Store(details.DownloadURL, reader, func() error {
+		if details.Checksum != "" && reader.Checksum() != details.Checksum {
+			return fmt.Errorf("expected checksum %q but got %q", details.Checksum, reader.Checksum())
+		}
+		return nil
+	})
+	if err != nil || details.Checksum == "" {
+		return err
+	}
+
+	return checksums.Store(details.DownloadURL, strings.NewReader(details.Checksum), nil)
+}
+
+// mirroredChecksum returns the checksum of the mirrored image, or an empty string if it is not mirrored.
+// The checksum stored with the image is used if present, otherwise the image is hashed once and the
+// checksum is stored.
+func mirroredChecksum(ctx context.Context, mirror, checksums *http.MirrorGetter, details *api.ArtifactDetails) string {
+	imagePath, err := mirror.Path(details.DownloadURL)
+	if err != nil {
+		return ""
+	}
+	if _, err = os.Stat(imagePath); err != nil {
+		return ""
+	}
+	if stored, readErr := checksums.GetAll(details.DownloadURL); readErr == nil {
+		return string(stored)
+	}
+
+	reader, err := mirror.GetWithChecksumAndContext(ctx, details.DownloadURL, details.ChecksumHash)
+	if err != nil {
+		return ""
+	}
+	defer reader.Close()
+
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return ""
+	}
+	if reader.Checksum() == details.Checksum {
+		// A failure only means that the image is hashed again on the next run
+		_ = checksums.Store(details.DownloadURL, strings.NewReader(details.Checksum), nil)
+	}
+
+	return reader.Checksum()
+}
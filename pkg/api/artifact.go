@@ -17,17 +17,71 @@ type ArtifactTestParams struct {
 	Username string
 	// PrivateKey is the private key used to log in into the VM.
 	PrivateKey interface{}
+	// UserData is the data the VM was provisioned with.
+	UserData docs.UserData
 }
 
 type ArtifactResult struct {
 	// Tags contains all tags the built containerdisk was tagged with.
 	Tags []string `json:",omitempty"`
+	// Digest is the digest of the image or image index all tags were promoted with.
+	Digest string `json:",omitempty"`
+	// Architectures contains the verification result per image architecture.
+	Architectures map[string]ArchitectureResult `json:",omitempty"`
+	// Targets contains the promotion result per target registry.
+	Targets map[string]TargetResult `json:",omitempty"`
 	// Stage is the current stage of the containerdisk
 	Stage string
 	// Err indicates if an error happened while creating, verifying or promoting a containerdisk.
 	Err string `json:",omitempty"`
 }
 
+type ArchitectureResult struct {
+	// Verified indicates that the containerdisk was successfully verified on this architecture.
+	Verified bool
+	// Err indicates if an error happened while verifying the containerdisk on this architecture.
+	Err string `json:",omitempty"`
+	// Diagnostics is the directory the diagnostics of a failed verification were stored in.
+	Diagnostics string `json:",omitempty"`
+	// BootMetrics were measured while verifying the containerdisk on this architecture.
+	BootMetrics *BootMetrics `json:",omitempty"`
+	// Configurations contains the results of additional VM configurations, e.g. "firmware=efi,bus=sata".
+	// They show which configurations boot, but do not affect whether the containerdisk is verified.
+	Configurations map[string]ConfigurationResult `json:",omitempty"`
+}
+
+// DefaultConfiguration is the name of the unchanged VM configuration of an artifact.
+const DefaultConfiguration = "default"
+
+type ConfigurationResult struct {
+	// Verified indicates that the containerdisk was successfully verified with this configuration.
+	Verified bool
+	// Err indicates if an error happened while verifying the containerdisk with this configuration.
+	Err string `json:",omitempty"`
+	// Diagnostics is the directory the diagnostics of a failed verification were stored in.
+	Diagnostics string `json:",omitempty"`
+}
+
+// BootMetrics track how fast a containerdisk boots and how much memory the guest needs.
+// Times are in seconds since the VM was created, zero values were not measured.
+type BootMetrics struct {
+	// Ready is the time until the VM was ready.
+	Ready float64 `json:",omitempty"`
+	// GuestAgentConnected is the time until the guest agent connected.
+	GuestAgentConnected float64 `json:",omitempty"`
+	// SSHReachable is the time until the user could log in over SSH.
+	SSHReachable float64 `json:",omitempty"`
+	// MemoryUsed is the memory in bytes used by the guest right after the guest agent connected.
+	MemoryUsed int64 `json:",omitempty"`
+}
+
+type TargetResult struct {
+	// DryRun indicates that nothing was copied to the target registry.
+	DryRun bool `json:",omitempty"`
+	// Err indicates if an error happened while promoting to the target registry.
+	Err string `json:",omitempty"`
+}
+
 type ArtifactDetails struct {
 	// Checksum is the checksum of the image to download.
 	Checksum string
@@ -35,6 +89,8 @@ type ArtifactDetails struct {
 	ChecksumHash func() hash.Hash
 	// DownloadURL points to the target image.
 	DownloadURL string
+	// MirrorURLs point to the same image on mirrors, they are tried in order if DownloadURL fails.
+	MirrorURLs []string
 	// ImageArchitecture is the target architecture of the image.
 	ImageArchitecture string
 	// Compression describes the compression format of the downloaded image.
@@ -2,6 +2,7 @@ package build
 
 import (
 	"fmt"
+	"slices"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
@@ -95,3 +96,10 @@ func ContainerDiskIndex(images []v1.Image) (v1.ImageIndex, error) {
 	idx := mutate.IndexMediaType(empty.Index, types.DockerManifestList)
 	return mutate.AppendManifests(idx, indexAddendum...), nil
 }
+
+// FilterIndex returns a copy of the image index which only contains the images for the given architectures.
+func FilterIndex(idx v1.ImageIndex, architectures []string) v1.ImageIndex {
+	return mutate.RemoveManifests(idx, func(desc v1.Descriptor) bool {
+		return desc.Platform == nil || !slices.Contains(architectures, desc.Platform.Architecture)
+	})
+}
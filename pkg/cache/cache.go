@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"syscall"
+	"time"
+)
+
+const (
+	lockFile = ".lock"
+	tmpDir   = "tmp"
+)
+
+var keyRegExp = regexp.MustCompile(`^[a-f0-9]+$`)
+
+// Cache stores verified, decompressed disks keyed by the checksum of their upstream artifact.
+// It can be shared by multiple workers and processes, all modifications are serialized with a lock file.
+// Entries are evicted in least recently used order once the cache exceeds its maximum size.
+type Cache struct {
+	dir     string
+	maxSize int64
+}
+
+func New(dir string, maxSize int64) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Join(dir, tmpDir), 0o755); err != nil {
+		return nil, fmt.Errorf("error creating the cache directory: %v", err)
+	}
+
+	return &Cache{dir: dir, maxSize: maxSize}, nil
+}
+
+// TempDir returns a directory for files which are later added with Put.
+// Files in this directory are on the same filesystem as the cache, so they can be added without copying.
+func (c *Cache) TempDir() string {
+	return filepath.Join(c.dir, tmpDir)
+}
+
+// Get returns a copy of the entry for key in TempDir, which the caller has to remove.
+// Evicting the entry does not affect the copy. If there is no entry, an empty string is returned.
+func (c *Cache) Get(key string) (file string, err error) {
+	if !keyRegExp.MatchString(key) {
+		return "", fmt.Errorf("invalid cache key %q", key)
+	}
+
+	err = c.withLock(func() error {
+		entry := filepath.Join(c.dir, key)
+		if _, statErr := os.Stat(entry); os.IsNotExist(statErr) {
+			return nil
+		}
+
+		now := time.Now()
+		if chtimesErr := os.Chtimes(entry, now, now); chtimesErr != nil {
+			return chtimesErr
+		}
+		file, err = c.link(entry)
+		return err
+	})
+
+	return file, err
+}
+
+// Put adds file as entry for key and evicts the least recently used entries if the cache is full.
+// The file is not modified and still owned by the caller.
+func (c *Cache) Put(key, file string) error {
+	if !keyRegExp.MatchString(key) {
+		return fmt.Errorf("invalid cache key %q", key)
+	}
+
+	return c.withLock(func() error {
+		entry := filepath.Join(c.dir, key)
+		tmp, err := c.link(file)
+		if err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, entry); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+
+		return c.evict()
+	})
+}
+
+// link hardlinks file into TempDir, or copies it if that is not possible.
+func (c *Cache) link(file string) (string, error) {
+	tmp, err := os.CreateTemp(c.TempDir(), "entry")
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+
+	if err := os.Link(file, tmp.Name()); err == nil {
+		return tmp.Name(), nil
+	}
+
+	if err := copyFile(file, tmp.Name()); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("error copying %s into the cache: %v", file, err)
+	}
+
+	return tmp.Name(), nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+func (c *Cache) evict() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	var (
+		entries []os.FileInfo
+		size    int64
+	)
+	for _, dirEntry := range dirEntries {
+		if !keyRegExp.MatchString(dirEntry.Name()) || !dirEntry.Type().IsRegular() {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, info)
+		size += info.Size()
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+	for _, entry := range entries {
+		if size <= c.maxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, entry.Name())); err != nil {
+			return err
+		}
+		size -= entry.Size()
+	}
+
+	return nil
+}
+
+func (c *Cache) withLock(fn func() error) error {
+	lock, err := os.OpenFile(filepath.Join(c.dir, lockFile), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening the cache lock: %v", err)
+	}
+	defer lock.Close()
+
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("error locking the cache: %v", err)
+	}
+	defer func() {
+		_ = syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+	}()
+
+	return fn()
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache", func() {
+	var (
+		dir   string
+		cache *Cache
+	)
+
+	writeFile := func(content string) string {
+		file := filepath.Join(GinkgoT().TempDir(), "disk.img")
+		Expect(os.WriteFile(file, []byte(content), 0o600)).To(Succeed())
+		return file
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir = GinkgoT().TempDir()
+		cache, err = New(dir, 10)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should return a copy of an entry which survives eviction", func() {
+		Expect(cache.Put("aaaa", writeFile("12345"))).To(Succeed())
+
+		file, err := cache.Get("aaaa")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filepath.Dir(file)).To(Equal(cache.TempDir()))
+
+		Expect(cache.Put("bbbb", writeFile("1234567890"))).To(Succeed())
+		Expect(filepath.Join(dir, "aaaa")).ToNot(BeAnExistingFile())
+		Expect(os.ReadFile(file)).To(BeEquivalentTo("12345"))
+	})
+
+	It("should return nothing for missing entries", func() {
+		file, err := cache.Get("aaaa")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file).To(BeEmpty())
+	})
+
+	It("should evict the least recently used entries", func() {
+		Expect(cache.Put("aaaa", writeFile("1234"))).To(Succeed())
+		Expect(cache.Put("bbbb", writeFile("1234"))).To(Succeed())
+		past := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(filepath.Join(dir, "aaaa"), past, past)).To(Succeed())
+		Expect(os.Chtimes(filepath.Join(dir, "bbbb"), past.Add(-time.Hour), past.Add(-time.Hour))).To(Succeed())
+
+		// Using bbbb makes aaaa the least recently used entry
+		_, err := cache.Get("bbbb")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cache.Put("cccc", writeFile("1234"))).To(Succeed())
+
+		Expect(filepath.Join(dir, "aaaa")).ToNot(BeAnExistingFile())
+		Expect(filepath.Join(dir, "bbbb")).To(BeAnExistingFile())
+		Expect(filepath.Join(dir, "cccc")).To(BeAnExistingFile())
+	})
+
+	It("should reject keys which are no checksums", func() {
+		Expect(cache.Put("../etc", writeFile("1234"))).To(MatchError(`invalid cache key "../etc"`))
+	})
+})
+
+func TestCache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cache Suite")
+}
@@ -5,4 +5,7 @@ ssh_authorized_keys:
   - {{.}}
   {{- else }}
   - ssh-rsa AAAA...
-  {{- end}}
\ No newline at end of file
+  {{- end}}
+{{- if .Hostname }}
+hostname: {{ .Hostname }}
+{{- end }}
\ No newline at end of file
@@ -35,4 +35,16 @@ virtctl create vm {{ if .Instancetype }}--instancetype={{ .Instancetype }} {{ en
 
 ```yaml
 {{ .Example -}}
-```
\ No newline at end of file
+```
+{{- if .Configurations }}
+
+## Verified configurations
+
+The following VM configurations were verified with KubeVirt:
+
+| Architecture | Configuration | Boots |
+|--------------|---------------|-------|
+{{- range .Configurations }}
+| {{ .Architecture }} | {{ .Configuration }} | {{ if .Verified }}yes{{ else }}no{{ end }} |
+{{- end }}
+{{- end }}
\ No newline at end of file
@@ -22,11 +22,22 @@ type TemplateData struct {
 	Image        string
 	Instancetype string
 	Preference   string
+	// Configurations are the VM configurations the containerdisk was verified with.
+	Configurations []VerifiedConfiguration
+}
+
+// VerifiedConfiguration is the result of verifying a VM configuration on an architecture.
+type VerifiedConfiguration struct {
+	Architecture  string
+	Configuration string
+	Verified      bool
 }
 
 type UserData struct {
 	Username       string
 	AuthorizedKeys []string
+	// Hostname is set as hostname of the guest if not empty.
+	Hostname string
 }
 
 type Option func(vm *v1.VirtualMachine)
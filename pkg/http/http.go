@@ -1,12 +1,16 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"fmt"
 	"hash"
 	"io"
 	"net/http"
+	"net/url"
+
+	"github.com/sirupsen/logrus"
 )
 
 type Getter interface {
@@ -21,28 +25,49 @@ type ReadCloserWithChecksum interface {
 	Checksum() string
 }
 
-type HTTPGetter struct{}
+type HTTPGetter struct {
+	// Client sends all requests, e.g. with the CA bundle and proxy of the network options.
+	// If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Log receives the progress of downloads. If nil, the standard logger is used.
+	Log *logrus.Entry
+	// Mirror serves all requests instead of the network if set, see RecordMirror.
+	Mirror *MirrorGetter
+	// RecordMirror makes GetAll and GetAllWithContext read from the network and store the metadata in Mirror
+	// instead. Other requests are sent to the network.
+	RecordMirror bool
+	// Metadata caches the files requested with GetAll and GetAllWithContext. If nil, every request is sent.
+	Metadata *MetadataCache
+	// Connections is the number of parallel range requests used to download from a host.
+	// Hosts without an entry use DefaultConnections, values below 2 download with a single stream.
+	Connections        map[string]int
+	DefaultConnections int
+	// TempDir is the directory parallel downloads are stored in until they are read, e.g. next to their
+	// destination. If empty, the default directory for temporary files is used.
+	TempDir string
+}
 
 func (h *HTTPGetter) GetAll(fileURL string) ([]byte, error) {
 	return h.GetAllWithContext(context.Background(), fileURL)
 }
 
+// GetAllWithContext returns the content of small metadata files, see MetadataCache for the caching behaviour.
 func (h *HTTPGetter) GetAllWithContext(ctx context.Context, fileURL string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, http.NoBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request to load primary repository file from %s: %v", fileURL, err)
+	if h.Mirror != nil && !h.RecordMirror {
+		return h.Mirror.GetAllWithContext(ctx, fileURL)
 	}
 
-	resp, err := http.DefaultClient.Do(req) //nolint:gosec // G704: request URL is controlled/trusted (not user input)
+	content, err := h.Metadata.get(ctx, h.client(), fileURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load primary repository file from %s: %v", fileURL, err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return nil, fmt.Errorf("failed to download %s: %v ", fileURL, fmt.Errorf("status : %v", resp.StatusCode))
+	if h.Mirror != nil {
+		if err := h.Mirror.Store(fileURL, bytes.NewReader(content), nil); err != nil {
+			return nil, err
+		}
 	}
-	return io.ReadAll(resp.Body)
+
+	return content, nil
 }
 
 func (h *HTTPGetter) GetWithChecksum(fileURL string, checksumHasher func() hash.Hash) (ReadCloserWithChecksum, error) {
@@ -53,12 +78,32 @@ func (h *HTTPGetter) GetWithChecksumAndContext(ctx context.Context, fileURL stri
 	readCloser ReadCloserWithChecksum,
 	err error,
 ) {
+	if h.Mirror != nil && !h.RecordMirror {
+		return h.Mirror.GetWithChecksumAndContext(ctx, fileURL, checksumHasher)
+	}
+
+	log := h.Log
+	if log == nil {
+		log = logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	if connections := h.connections(fileURL); connections > 1 {
+		file, downloadErr := parallelDownload(ctx, h.client(), fileURL, h.TempDir, connections, log)
+		if downloadErr != nil {
+			return nil, downloadErr
+		}
+		// The checksum is computed while the caller reads the completed download
+		if file != nil {
+			return newReadCloserWithChecksum(&tempFileBody{File: file}, checksumHasher), nil
+		}
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request to load primary repository file from %s: %v", fileURL, err)
 	}
 
-	resp, err := http.DefaultClient.Do(req) //nolint:gosec // G704: request URL is controlled/trusted (not user input)
+	resp, err := h.client().Do(req) //nolint:gosec // G704: request URL is controlled/trusted (not user input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load primary repository file from %s: %v", fileURL, err)
 	}
@@ -67,7 +112,28 @@ func (h *HTTPGetter) GetWithChecksumAndContext(ctx context.Context, fileURL stri
 		resp.Body.Close()
 		return nil, fmt.Errorf("failed to download %s: %v ", fileURL, fmt.Errorf("status : %v", resp.StatusCode))
 	}
-	return newReadCloserWithChecksum(resp.Body, checksumHasher), nil
+
+	return newReadCloserWithChecksum(newResumableBody(ctx, h.client(), fileURL, resp, log), checksumHasher), nil
+}
+
+func (h *HTTPGetter) client() *http.Client {
+	if h.Client == nil {
+		return http.DefaultClient
+	}
+
+	return h.Client
+}
+
+func (h *HTTPGetter) connections(fileURL string) int {
+	parsedURL, err := url.Parse(fileURL)
+	if err != nil {
+		return h.DefaultConnections
+	}
+	if connections, ok := h.Connections[parsedURL.Hostname()]; ok {
+		return connections
+	}
+
+	return h.DefaultConnections
 }
 
 func newReadCloserWithChecksum(body io.ReadCloser, checksumHasher func() hash.Hash) *readCloserWithChecksum {
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPGetter", func() {
+	var (
+		content  []byte
+		etag     string
+		requests int
+		server   *httptest.Server
+	)
+
+	BeforeEach(func() {
+		content = make([]byte, 1024*1024)
+		_, err := rand.Read(content)
+		Expect(err).ToNot(HaveOccurred())
+		etag = `"v1"`
+		requests = 0
+
+		resumeBackoff = 0
+		DeferCleanup(func() { resumeBackoff = 2 * time.Second })
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("Range") != "" {
+				http.ServeContent(w, r, "disk.img", time.Time{}, bytes.NewReader(content))
+				return
+			}
+
+			// Drop the connection after sending half of the content
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(content[:len(content)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("should resume an interrupted download and keep the checksum", func() {
+		getter := &HTTPGetter{}
+		reader, err := getter.GetWithChecksumAndContext(context.Background(), server.URL, sha256.New)
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(content))
+		Expect(requests).To(Equal(2))
+
+		checksum := sha256.Sum256(content)
+		Expect(reader.Checksum()).To(Equal(hex.EncodeToString(checksum[:])))
+	})
+
+	It("should fail if the content changed before resuming", func() {
+		getter := &HTTPGetter{}
+		reader, err := getter.GetWithChecksumAndContext(context.Background(), server.URL, sha256.New)
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+
+		etag = `"v2"`
+		_, err = io.ReadAll(reader)
+		Expect(err).To(MatchError(ContainSubstring("failed to download")))
+	})
+
+	Context("with multiple connections", func() {
+		var rangeRequests atomic.Int32
+
+		BeforeEach(func() {
+			rangeRequests.Store(0)
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Range") != "" {
+					rangeRequests.Add(1)
+				}
+				w.Header().Set("ETag", etag)
+				http.ServeContent(w, r, "disk.img", time.Time{}, bytes.NewReader(content))
+			})
+		})
+
+		It("should download chunks in parallel and compute the checksum", func() {
+			tempDir := GinkgoT().TempDir()
+			getter := &HTTPGetter{DefaultConnections: 4, TempDir: tempDir}
+			reader, err := getter.GetWithChecksumAndContext(context.Background(), server.URL, sha256.New)
+			Expect(err).ToNot(HaveOccurred())
+
+			data, err := io.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(content))
+			Expect(rangeRequests.Load()).To(BeEquivalentTo(4))
+
+			checksum := sha256.Sum256(content)
+			Expect(reader.Checksum()).To(Equal(hex.EncodeToString(checksum[:])))
+
+			file := reader.(*readCloserWithChecksum).body.(*tempFileBody).Name()
+			Expect(filepath.Dir(file)).To(Equal(tempDir))
+			Expect(reader.Close()).To(Succeed())
+			Expect(file).ToNot(BeAnExistingFile())
+		})
+
+		It("should fall back to a single stream without range support", func() {
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write(content)
+			})
+
+			getter := &HTTPGetter{Connections: map[string]int{"127.0.0.1": 4}}
+			reader, err := getter.GetWithChecksumAndContext(context.Background(), server.URL, sha256.New)
+			Expect(err).ToNot(HaveOccurred())
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(content))
+			Expect(reader.(*readCloserWithChecksum).body).To(BeAssignableToTypeOf(&resumableBody{}))
+		})
+	})
+})
+
+var _ = Describe("Metadata", func() {
+	var (
+		dir      string
+		requests atomic.Int32
+		server   *httptest.Server
+		getter   *HTTPGetter
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		metadata, err := NewMetadataCache(dir)
+		Expect(err).ToNot(HaveOccurred())
+		getter = &HTTPGetter{Metadata: metadata}
+		requests.Store(0)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_, _ = w.Write([]byte("SHA256 (disk.img) = 1234"))
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("should request every URL only once per run", func() {
+		for range 3 {
+			Expect(getter.GetAll(server.URL)).To(BeEquivalentTo("SHA256 (disk.img) = 1234"))
+		}
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should use the persisted metadata if it was not modified", func() {
+		Expect(getter.GetAll(server.URL)).To(BeEquivalentTo("SHA256 (disk.img) = 1234"))
+
+		// Simulate the next run
+		var err error
+		getter.Metadata, err = NewMetadataCache(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getter.GetAll(server.URL)).To(BeEquivalentTo("SHA256 (disk.img) = 1234"))
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("should not cache failed requests", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		_, err := getter.GetAll(server.URL)
+		Expect(err).To(HaveOccurred())
+		_, err = getter.GetAll(server.URL)
+		Expect(err).To(HaveOccurred())
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("should request every URL without a cache", func() {
+		getter.Metadata = nil
+		for range 2 {
+			Expect(getter.GetAll(server.URL)).To(BeEquivalentTo("SHA256 (disk.img) = 1234"))
+		}
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+})
+
+var _ = Describe("MirrorGetter", func() {
+	var mirror *MirrorGetter
+
+	BeforeEach(func() {
+		mirror = &MirrorGetter{Dir: GinkgoT().TempDir()}
+	})
+
+	DescribeTable("Path should map URLs into the mirror directory",
+		func(fileURL, expected string) {
+			filePath, err := mirror.Path(fileURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filePath).To(Equal(filepath.Join(mirror.Dir, expected)))
+		},
+		Entry("simple path", "https://cloud.centos.org/centos/10-stream/CHECKSUM", "cloud.centos.org/centos/10-stream/CHECKSUM"),
+		Entry("host with port", "http://127.0.0.1:8080/disk.img", "127.0.0.1:8080/disk.img"),
+		Entry("path escaping the mirror", "https://example.com/../../etc/passwd", "example.com/etc/passwd"),
+	)
+
+	It("should serve all requests of HTTPGetters from the mirror", func() {
+		Expect(mirror.Store("https://example.com/SHA256SUMS", strings.NewReader("checksums"), nil)).To(Succeed())
+		Expect(mirror.Store("https://example.com/disk.img", strings.NewReader("disk"), nil)).To(Succeed())
+		getter := &HTTPGetter{Mirror: mirror}
+		Expect(getter.GetAll("https://example.com/SHA256SUMS")).To(BeEquivalentTo("checksums"))
+
+		reader, err := getter.GetWithChecksum("https://example.com/disk.img", sha256.New)
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+		Expect(io.ReadAll(reader)).To(BeEquivalentTo("disk"))
+		checksum := sha256.Sum256([]byte("disk"))
+		Expect(reader.Checksum()).To(Equal(hex.EncodeToString(checksum[:])))
+	})
+
+	It("should record metadata into the mirror", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("checksums"))
+		}))
+		DeferCleanup(server.Close)
+		getter := &HTTPGetter{Mirror: mirror, RecordMirror: true}
+		Expect(getter.GetAll(server.URL + "/SHA256SUMS")).To(BeEquivalentTo("checksums"))
+		Expect(mirror.GetAll(server.URL + "/SHA256SUMS")).To(BeEquivalentTo("checksums"))
+	})
+
+	It("should not store content which fails verification", func() {
+		err := mirror.Store("https://example.com/disk.img", strings.NewReader("corrupted"), func() error {
+			return errors.New("checksum mismatch")
+		})
+		Expect(err).To(MatchError("checksum mismatch"))
+		_, err = mirror.GetAll("https://example.com/disk.img")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func TestHTTP(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HTTP Suite")
+}
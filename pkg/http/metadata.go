@@ -0,0 +1,182 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MetadataCache caches upstream metadata files like checksum files and release lists.
+// Every URL is only requested once. If a cache directory is configured, responses are persisted
+// and later requests are conditional on their ETag and Last-Modified.
+type MetadataCache struct {
+	dir string
+
+	mu      sync.Mutex
+	entries map[string]*metadataEntry
+}
+
+// NewMetadataCache returns a cache which persists the metadata in dir. If dir is empty, metadata is only
+// cached in memory.
+func NewMetadataCache(dir string) (*MetadataCache, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("error creating the metadata cache directory: %v", err)
+		}
+	}
+
+	return &MetadataCache{dir: dir, entries: map[string]*metadataEntry{}}, nil
+}
+
+type metadataEntry struct {
+	mu   sync.Mutex
+	done bool
+	body []byte
+}
+
+// storedMetadata is the format of a response persisted in the cache directory.
+type storedMetadata struct {
+	URL          string
+	ETag         string `json:",omitempty"`
+	LastModified string `json:",omitempty"`
+	Body         []byte
+}
+
+func (c *MetadataCache) get(ctx context.Context, client *http.Client, fileURL string) ([]byte, error) {
+	// Without a cache every request is sent
+	if c == nil {
+		return (&MetadataCache{}).fetch(ctx, client, fileURL)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[fileURL]
+	if !ok {
+		entry = &metadataEntry{}
+		c.entries[fileURL] = entry
+	}
+	c.mu.Unlock()
+
+	// Concurrent requests for the same URL wait for the first one, failed requests are not cached.
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if !entry.done {
+		body, err := c.fetch(ctx, client, fileURL)
+		if err != nil {
+			return nil, err
+		}
+		entry.body = body
+		entry.done = true
+	}
+
+	return bytes.Clone(entry.body), nil
+}
+
+func (c *MetadataCache) fetch(ctx context.Context, client *http.Client, fileURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request to load primary repository file from %s: %v", fileURL, err)
+	}
+
+	stored := c.load(fileURL)
+	if stored != nil {
+		if stored.ETag != "" {
+			req.Header.Set("If-None-Match", stored.ETag)
+		}
+		if stored.LastModified != "" {
+			req.Header.Set("If-Modified-Since", stored.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req) //nolint:gosec // G704: request URL is controlled/trusted (not user input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load primary repository file from %s: %v", fileURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && stored != nil {
+		return stored.Body, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to download %s: %v ", fileURL, fmt.Errorf("status : %v", resp.StatusCode))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.store(&storedMetadata{
+		URL:          fileURL,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Body:         body,
+	})
+
+	return body, nil
+}
+
+func (c *MetadataCache) path(fileURL string) string {
+	sum := sha256.Sum256([]byte(fileURL))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load returns the persisted response for fileURL, or nil if there is none.
+func (c *MetadataCache) load(fileURL string) *storedMetadata {
+	if c.dir == "" {
+		return nil
+	}
+
+	raw, err := os.ReadFile(c.path(fileURL))
+	if err != nil {
+		return nil
+	}
+	stored := &storedMetadata{}
+	if err := json.Unmarshal(raw, stored); err != nil || stored.URL != fileURL {
+		return nil
+	}
+
+	return stored
+}
+
+func (c *MetadataCache) store(stored *storedMetadata) {
+	if c.dir == "" || (stored.ETag == "" && stored.LastModified == "") {
+		return
+	}
+
+	if err := c.write(stored); err != nil {
+		logrus.Warnf("Error caching %s: %v", stored.URL, err)
+	}
+}
+
+// write persists stored through a temporary file, other processes may read the cache concurrently.
+func (c *MetadataCache) write(stored *storedMetadata) error {
+	raw, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, "metadata")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path(stored.URL))
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// MirrorGetter serves upstream URLs from a local directory tree, "https://host/path" is read from "<Dir>/host/path".
+type MirrorGetter struct {
+	Dir string
+}
+
+// Path returns the location of fileURL in the mirror.
+func (m *MirrorGetter) Path(fileURL string) (string, error) {
+	parsedURL, err := url.Parse(fileURL)
+	if err != nil {
+		return "", err
+	}
+	if parsedURL.Host == "" {
+		return "", fmt.Errorf("URL %q has no host", fileURL)
+	}
+
+	// Cleaning the rooted path prevents escaping the mirror directory
+	return filepath.Join(m.Dir, parsedURL.Host, filepath.FromSlash(path.Clean("/"+parsedURL.Path))), nil
+}
+
+func (m *MirrorGetter) GetAll(fileURL string) ([]byte, error) {
+	return m.GetAllWithContext(context.Background(), fileURL)
+}
+
+func (m *MirrorGetter) GetAllWithContext(_ context.Context, fileURL string) ([]byte, error) {
+	filePath, err := m.Path(fileURL)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s from the upstream mirror: %v", fileURL, err)
+	}
+
+	return content, nil
+}
+
+func (m *MirrorGetter) GetWithChecksum(fileURL string, checksumHasher func() hash.Hash) (ReadCloserWithChecksum, error) {
+	return m.GetWithChecksumAndContext(context.Background(), fileURL, checksumHasher)
+}
+
+func (m *MirrorGetter) GetWithChecksumAndContext(_ context.Context, fileURL string, checksumHasher func() hash.Hash) (
+	ReadCloserWithChecksum, error,
+) {
+	filePath, err := m.Path(fileURL)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s from the upstream mirror: %v", fileURL, err)
+	}
+
+	return newReadCloserWithChecksum(file, checksumHasher), nil
+}
+
+// Store writes the content of fileURL to the mirror. The file is replaced atomically, so concurrent readers
+// never see partial content. If verify is not nil, it is called after reading content and the file is only
+// replaced if it succeeds.
+func (m *MirrorGetter) Store(fileURL string, content io.Reader, verify func() error) error {
+	filePath, err := m.Path(fileURL)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".download")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error storing %s in the upstream mirror: %v", fileURL, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if verify != nil {
+		if err := verify(); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+
+	return os.Rename(tmp.Name(), filePath)
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// parallelDownload downloads fileURL with multiple range requests into a preallocated temporary file in tempDir.
+// If the server does not support range requests, nil is returned and the caller should fall back to
+// a single stream.
+func parallelDownload(ctx context.Context, client *http.Client, fileURL, tempDir string, connections int, log *logrus.Entry,
+) (*os.File, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, fileURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request to download %s: %v", fileURL, err)
+	}
+	resp, err := client.Do(req) //nolint:gosec // G704: request URL is controlled/trusted (not user input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", fileURL, err)
+	}
+	resp.Body.Close()
+
+	validator := rangeValidator(resp.Header)
+	size := resp.ContentLength
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Accept-Ranges") != "bytes" || validator == "" || size <= 0 {
+		log.Infof("%s does not support range requests, downloading with a single connection", fileURL)
+		return nil, nil
+	}
+
+	file, err := os.CreateTemp(tempDir, "download")
+	if err != nil {
+		return nil, err
+	}
+	// Request all chunks from the same mirror if the server redirected
+	if err := downloadChunks(ctx, client, file, resp.Request.URL.String(), validator, size, connections, log); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func downloadChunks(ctx context.Context, client *http.Client, file *os.File, fileURL, validator string, size int64, connections int,
+	log *logrus.Entry,
+) error {
+	if err := file.Truncate(size); err != nil {
+		return fmt.Errorf("error preallocating the download: %v", err)
+	}
+
+	log.Infof("Downloading %s with %d connections", fileURL, connections)
+	progress := newProgress(log, size)
+	chunkSize := (size + int64(connections) - 1) / int64(connections)
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for start := int64(0); start < size; start += chunkSize {
+		chunk := &resumableBody{
+			ctx:       groupCtx,
+			client:    client,
+			fileURL:   fileURL,
+			log:       log,
+			progress:  progress,
+			validator: validator,
+			resumable: true,
+			offset:    start,
+			end:       min(start+chunkSize, size) - 1,
+		}
+		group.Go(func() error {
+			return chunk.writeTo(file)
+		})
+	}
+
+	return group.Wait()
+}
+
+// writeTo writes the range of the chunk to the same offset in file.
+func (r *resumableBody) writeTo(file *os.File) error {
+	start, length := r.offset, r.end-r.offset+1
+
+	body, err := r.requestRange()
+	if err != nil {
+		return err
+	}
+	r.body = body
+	defer r.Close()
+
+	written, err := io.Copy(io.NewOffsetWriter(file, start), io.LimitReader(r, length))
+	if err != nil {
+		return fmt.Errorf("error writing bytes %d-%d of the download: %v", start, r.end, err)
+	}
+	if written != length {
+		return fmt.Errorf("download of bytes %d-%d ended after %d bytes", start, r.end, written)
+	}
+
+	return nil
+}
+
+// tempFileBody reads a completed download and removes it on Close.
+type tempFileBody struct {
+	*os.File
+}
+
+func (t *tempFileBody) Close() error {
+	closeErr := t.File.Close()
+	if err := os.Remove(t.Name()); err != nil {
+		return err
+	}
+
+	return closeErr
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxResumes is the number of consecutive resumes without progress after which a download fails.
+	maxResumes       = 5
+	progressInterval = 30 * time.Second
+	mebibyte         = 1024 * 1024
+)
+
+// resumeBackoff is multiplied with the number of failed attempts before resuming a download.
+var resumeBackoff = 2 * time.Second
+
+// resumableBody reads a response body and transparently continues the download with a
+// range request when the connection drops. The data returned by Read is therefore
+// identical to the data of an uninterrupted download, so a hasher reading from it
+// keeps its state across resumes.
+type resumableBody struct {
+	ctx      context.Context
+	client   *http.Client
+	fileURL  string
+	log      *logrus.Entry
+	progress *progress
+
+	body io.ReadCloser
+	// validator is the ETag or Last-Modified header of the first response, used for If-Range.
+	validator string
+	resumable bool
+	// offset is the position of the next byte to read in the remote file.
+	offset int64
+	// end is the inclusive end of the requested range, or -1 to read until the end of the file.
+	end      int64
+	failures int
+}
+
+func newResumableBody(ctx context.Context, client *http.Client, fileURL string, resp *http.Response, log *logrus.Entry,
+) *resumableBody {
+	validator := rangeValidator(resp.Header)
+	return &resumableBody{
+		ctx:       ctx,
+		client:    client,
+		fileURL:   fileURL,
+		log:       log,
+		progress:  newProgress(log, resp.ContentLength),
+		body:      resp.Body,
+		validator: validator,
+		resumable: resp.Header.Get("Accept-Ranges") == "bytes" && validator != "",
+		end:       -1,
+	}
+}
+
+// rangeValidator returns the header value to use for If-Range, weak ETags must not be used for range requests.
+func rangeValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return header.Get("Last-Modified")
+}
+
+func (r *resumableBody) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			r.failures = 0
+			r.progress.add(int64(n))
+		}
+
+		if err == nil || errors.Is(err, io.EOF) {
+			return n, err
+		}
+		if resumeErr := r.resume(err); resumeErr != nil {
+			return n, resumeErr
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *resumableBody) resume(readErr error) error {
+	if !r.resumable || r.ctx.Err() != nil {
+		return readErr
+	}
+	r.body.Close()
+	r.log.Warnf("Download of %s interrupted at byte %d, resuming: %v", r.fileURL, r.offset, readErr)
+
+	for {
+		r.failures++
+		if r.failures > maxResumes {
+			return fmt.Errorf("giving up after %d attempts to resume the download: %w", maxResumes, readErr)
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		case <-time.After(time.Duration(r.failures) * resumeBackoff):
+		}
+
+		body, err := r.requestRange()
+		if err != nil {
+			var statusErr *rangeStatusError
+			if errors.As(err, &statusErr) {
+				return err
+			}
+			readErr = err
+			continue
+		}
+		r.body = body
+
+		return nil
+	}
+}
+
+type rangeStatusError struct {
+	fileURL    string
+	offset     int64
+	statusCode int
+}
+
+func (e *rangeStatusError) Error() string {
+	return fmt.Sprintf("failed to download %s from byte %d: status %v", e.fileURL, e.offset, e.statusCode)
+}
+
+// requestRange requests the remaining range of the file. It fails if the file changed since the first request.
+func (r *resumableBody) requestRange() (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.fileURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if r.end < 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", r.offset, r.end))
+	}
+	req.Header.Set("If-Range", r.validator)
+
+	resp, err := r.client.Do(req) //nolint:gosec // G704: request URL is controlled/trusted (not user input)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPartialContent || !contentRangeStartsAt(resp.Header.Get("Content-Range"), r.offset) {
+		resp.Body.Close()
+		return nil, &rangeStatusError{fileURL: r.fileURL, offset: r.offset, statusCode: resp.StatusCode}
+	}
+
+	return resp.Body, nil
+}
+
+// contentRangeStartsAt checks that a Content-Range header like "bytes 100-199/200" starts at offset.
+func contentRangeStartsAt(contentRange string, offset int64) bool {
+	rangeSpec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return false
+	}
+	start, _, ok := strings.Cut(rangeSpec, "-")
+	if !ok {
+		return false
+	}
+	startOffset, err := strconv.ParseInt(start, 10, 64)
+	return err == nil && startOffset == offset
+}
+
+func (r *resumableBody) Close() error {
+	return r.body.Close()
+}
+
+// progress periodically logs the progress of a download, it is shared by all connections of a download.
+type progress struct {
+	log  *logrus.Entry
+	size int64
+
+	mu           sync.Mutex
+	done         int64
+	lastProgress time.Time
+	lastDone     int64
+}
+
+func newProgress(log *logrus.Entry, size int64) *progress {
+	return &progress{log: log, size: size, lastProgress: time.Now()}
+}
+
+func (p *progress) add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done += n
+	now := time.Now()
+	elapsed := now.Sub(p.lastProgress)
+	if elapsed < progressInterval && p.done != p.size {
+		return
+	}
+
+	rate := float64(p.done-p.lastDone) / mebibyte / elapsed.Seconds()
+	if p.size > 0 {
+		p.log.Infof("Downloaded %d%% (%d of %d MiB) at %.1f MiB/s",
+			p.done*100/p.size, p.done/mebibyte, p.size/mebibyte, rate)
+	} else {
+		p.log.Infof("Downloaded %d MiB at %.1f MiB/s", p.done/mebibyte, rate)
+	}
+	p.lastProgress = now
+	p.lastDone = p.done
+}
@@ -0,0 +1,184 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+type Options struct {
+	// CABundle is a PEM file with CA certificates which are trusted in addition to the system CAs.
+	CABundle string
+	// ClientCert and ClientKey are a PEM encoded client certificate and key used for mTLS.
+	ClientCert string
+	ClientKey  string
+	// HTTPProxy, HTTPSProxy and NoProxy override the corresponding environment variables.
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// Config contains the TLS and proxy configuration for all outgoing connections.
+// A nil Config uses the defaults of the respective clients.
+type Config struct {
+	transport         *http.Transport
+	insecureTransport *http.Transport
+	proxy             func(*url.URL) (*url.URL, error)
+	certDir           string
+}
+
+func New(o *Options) (*Config, error) {
+	tlsConfig, err := newTLSConfig(o)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyConfig := httpproxy.FromEnvironment()
+	if o.HTTPProxy != "" {
+		proxyConfig.HTTPProxy = o.HTTPProxy
+	}
+	if o.HTTPSProxy != "" {
+		proxyConfig.HTTPSProxy = o.HTTPSProxy
+	}
+	if o.NoProxy != "" {
+		proxyConfig.NoProxy = o.NoProxy
+	}
+
+	c := &Config{
+		proxy: proxyConfig.ProxyFunc(),
+	}
+
+	c.transport = http.DefaultTransport.(*http.Transport).Clone()
+	c.transport.TLSClientConfig = tlsConfig
+	c.transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return c.proxy(req.URL)
+	}
+
+	c.insecureTransport = c.transport.Clone()
+	c.insecureTransport.TLSClientConfig.InsecureSkipVerify = true
+
+	if o.CABundle != "" || o.ClientCert != "" {
+		if c.certDir, err = newCertDir(o); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func newTLSConfig(o *Options) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if o.CABundle != "" {
+		pem, err := os.ReadFile(o.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the CA bundle: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("error loading the system CAs: %v", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if o.ClientCert != "" || o.ClientKey != "" {
+		if o.ClientCert == "" || o.ClientKey == "" {
+			return nil, errors.New("client certificate and key have to be specified together")
+		}
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// newCertDir creates a directory in the layout expected by containers-certs.d(5), which is
+// how the podman based clients are configured with CAs and client certificates.
+func newCertDir(o *Options) (string, error) {
+	dir, err := os.MkdirTemp("", "medius-certs")
+	if err != nil {
+		return "", err
+	}
+
+	links := map[string]string{
+		"ca.crt":      o.CABundle,
+		"client.cert": o.ClientCert,
+		"client.key":  o.ClientKey,
+	}
+	for name, target := range links {
+		if target == "" {
+			continue
+		}
+		target, err = filepath.Abs(target)
+		if err == nil {
+			err = os.Symlink(target, filepath.Join(dir, name))
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("error preparing the certificate directory: %v", err)
+		}
+	}
+
+	return dir, nil
+}
+
+// Transport returns the transport to use for HTTP requests. If insecure is true,
+// the returned transport does not verify server certificates.
+func (c *Config) Transport(insecure bool) http.RoundTripper {
+	if c == nil {
+		return http.DefaultTransport
+	}
+	if insecure {
+		return c.insecureTransport
+	}
+
+	return c.transport
+}
+
+// Client returns an HTTP client using the verifying transport.
+func (c *Config) Client() *http.Client {
+	return &http.Client{Transport: c.Transport(false)}
+}
+
+// Proxy returns the proxy to use for the given request URL, it has the signature podman expects.
+func (c *Config) Proxy(reqURL *url.URL) (*url.URL, error) {
+	if c == nil {
+		return httpproxy.FromEnvironment().ProxyFunc()(reqURL)
+	}
+
+	return c.proxy(reqURL)
+}
+
+// CertDir returns a containers-certs.d(5) directory with the configured CAs and client certificates.
+// It is empty if neither were configured.
+func (c *Config) CertDir() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.certDir
+}
+
+// Close removes temporary files created for the configuration.
+func (c *Config) Close() error {
+	if c == nil || c.certDir == "" {
+		return nil
+	}
+
+	return os.RemoveAll(c.certDir)
+}
@@ -0,0 +1,93 @@
+package network
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Network", func() {
+	Context("with a TLS server using a custom CA", func() {
+		var (
+			server   *httptest.Server
+			caBundle string
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			DeferCleanup(server.Close)
+
+			caBundle = filepath.Join(GinkgoT().TempDir(), "ca.pem")
+			caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			Expect(os.WriteFile(caBundle, caPEM, 0o600)).To(Succeed())
+		})
+
+		It("should trust the CA bundle", func() {
+			c, err := New(&Options{CABundle: caBundle})
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(c.Close)
+
+			resp, err := c.Client().Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should reject the server without the CA bundle unless insecure", func() {
+			c, err := New(&Options{})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = c.Client().Get(server.URL)
+			Expect(err).To(MatchError(ContainSubstring("certificate")))
+
+			resp, err := (&http.Client{Transport: c.Transport(true)}).Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+		})
+
+		It("should link the CA bundle into the certificate directory", func() {
+			c, err := New(&Options{CABundle: caBundle})
+			Expect(err).ToNot(HaveOccurred())
+
+			certDir := c.CertDir()
+			Expect(os.ReadFile(filepath.Join(certDir, "ca.crt"))).ToNot(BeEmpty())
+			Expect(c.Close()).To(Succeed())
+			Expect(certDir).ToNot(BeADirectory())
+		})
+	})
+
+	It("should require the client certificate and key together", func() {
+		_, err := New(&Options{ClientCert: "client.pem"})
+		Expect(err).To(MatchError("client certificate and key have to be specified together"))
+	})
+
+	It("should override the proxy environment", func() {
+		GinkgoT().Setenv("HTTPS_PROXY", "http://env-proxy:3128")
+		GinkgoT().Setenv("NO_PROXY", "")
+
+		c, err := New(&Options{HTTPSProxy: "http://proxy:3128", NoProxy: "internal.example.com"})
+		Expect(err).ToNot(HaveOccurred())
+
+		proxy, err := c.Proxy(&url.URL{Scheme: "https", Host: "quay.io"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(proxy).To(Equal(&url.URL{Scheme: "http", Host: "proxy:3128"}))
+
+		proxy, err = c.Proxy(&url.URL{Scheme: "https", Host: "internal.example.com"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(proxy).To(BeNil())
+	})
+})
+
+func TestNetwork(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Network Suite")
+}
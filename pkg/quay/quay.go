@@ -20,6 +20,7 @@ type QuayClient interface {
 type quayClient struct {
 	tokenFile string
 	org       string
+	client    *http.Client
 }
 
 func (q *quayClient) base(repository string) url.URL {
@@ -54,8 +55,7 @@ func (q *quayClient) json(ctx context.Context, method, repo, subresource string,
 	repoURL.Path = path.Join(repoURL.Path, subresource)
 	req, _ := http.NewRequestWithContext(ctx, method, repoURL.String(), bytes.NewBuffer(content))
 	req.Header = header
-	cli := &http.Client{}
-	resp, err := cli.Do(req) //nolint:gosec // G704: client talks only to Quay API / controlled endpoint
+	resp, err := q.client.Do(req) //nolint:gosec // G704: client talks only to Quay API / controlled endpoint
 	if err != nil {
 		return fmt.Errorf("error performing rest call: %v", err)
 	}
@@ -78,8 +78,8 @@ func (q *quayClient) Update(ctx context.Context, repository, description string)
 	return nil
 }
 
-func NewQuayClient(tokenFile, org string) *quayClient {
-	return &quayClient{tokenFile: tokenFile, org: org}
+func NewQuayClient(tokenFile, org string, client *http.Client) *quayClient {
+	return &quayClient{tokenFile: tokenFile, org: org, client: client}
 }
 
 type Description struct {
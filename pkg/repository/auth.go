@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/google/go-containerregistry/pkg/authn"
+	crname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"go.podman.io/image/v5/docker"
+	"go.podman.io/image/v5/pkg/docker/config"
+	"go.podman.io/image/v5/types"
+)
+
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// AuthConfig configures the credentials used to authenticate against registries.
+// Credentials are resolved in the following order:
+// Registries, Credentials, AuthFile and finally the default credential locations of containers-auth.json(5).
+type AuthConfig struct {
+	// AuthFile is an optional containers-auth.json(5) file with registry credentials.
+	AuthFile string
+	// Credentials are used for all registries which have no entry in Registries.
+	Credentials *Credentials
+	// Registries maps registries or repositories, e.g. "quay.io" or "quay.io/containerdisks", to credentials.
+	// The most specific entry wins.
+	Registries map[string]Credentials
+}
+
+// NewCredentials reads the password of username from passwordFile.
+func NewCredentials(username, passwordFile string) (*Credentials, error) {
+	if username == "" || passwordFile == "" {
+		return nil, errors.New("username and password file have to be specified together")
+	}
+
+	rawPassword, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the password file: %v", err)
+	}
+
+	return &Credentials{Username: username, Password: strings.TrimSpace(string(rawPassword))}, nil
+}
+
+// credentials returns the credentials for key, which is a registry or a repository.
+// If no credentials are found, an empty DockerAuthConfig is returned.
+func (a *AuthConfig) credentials(key string) (types.DockerAuthConfig, error) {
+	key = normalizeAuthKey(key)
+
+	if creds, ok := a.registryCredentials(key); ok {
+		return types.DockerAuthConfig{Username: creds.Username, Password: creds.Password}, nil
+	}
+	if a.Credentials != nil {
+		return types.DockerAuthConfig{Username: a.Credentials.Username, Password: a.Credentials.Password}, nil
+	}
+	if a.AuthFile != "" {
+		creds, err := config.GetCredentials(&types.SystemContext{AuthFilePath: a.AuthFile}, key)
+		if err != nil || creds != (types.DockerAuthConfig{}) {
+			return creds, err
+		}
+	}
+
+	return config.GetCredentials(nil, key)
+}
+
+func (a *AuthConfig) registryCredentials(key string) (Credentials, bool) {
+	for {
+		if creds, ok := a.Registries[key]; ok {
+			return creds, true
+		}
+
+		lastSlash := strings.LastIndex(key, "/")
+		if lastSlash == -1 {
+			return Credentials{}, false
+		}
+		key = key[:lastSlash]
+	}
+}
+
+// normalizeAuthKey maps the Docker Hub registry name used by crane to the name used in auth files.
+func normalizeAuthKey(key string) string {
+	if after, ok := strings.CutPrefix(key, crname.DefaultRegistry+"/"); ok {
+		return "docker.io/" + after
+	}
+	if key == crname.DefaultRegistry {
+		return "docker.io"
+	}
+
+	return key
+}
+
+// systemContextCredentials returns the credentials for imgRef in the form podman expects them.
+func (a *AuthConfig) systemContextCredentials(imgRef string) (*types.DockerAuthConfig, error) {
+	ref, err := crname.ParseReference(imgRef)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := a.credentials(ref.Context().String())
+	if err != nil {
+		return nil, err
+	}
+	if creds == (types.DockerAuthConfig{}) {
+		return nil, nil
+	}
+
+	return &creds, nil
+}
+
+// Resolve implements authn.Keychain, so that crane uses the same credentials as the podman based ImageMetadata.
+func (a *AuthConfig) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	creds, err := a.credentials(target.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if creds == (types.DockerAuthConfig{}) {
+		return authn.Anonymous, nil
+	}
+
+	return authn.FromConfig(authn.AuthConfig{
+		Username:      creds.Username,
+		Password:      creds.Password,
+		IdentityToken: creds.IdentityToken,
+	}), nil
+}
+
+func IsAuthError(err error) bool {
+	var unauthorized docker.ErrUnauthorizedForCredentials
+	if errors.As(err, &unauthorized) {
+		return true
+	}
+
+	if hasTransportErrorCode(err, transport.UnauthorizedErrorCode) || hasTransportErrorCode(err, transport.DeniedErrorCode) {
+		return true
+	}
+
+	ec := getErrorCode(err)
+	if ec == nil {
+		return false
+	}
+
+	switch ec.ErrorCode() {
+	case errcode.ErrorCodeUnauthorized, errcode.ErrorCodeDenied:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	crname "github.com/google/go-containerregistry/pkg/name"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Auth", func() {
+	var authFile string
+
+	BeforeEach(func() {
+		authFile = filepath.Join(GinkgoT().TempDir(), "auth.json")
+		// "file:secret" and "docker:hub" base64 encoded
+		Expect(os.WriteFile(authFile, []byte(`{"auths": {
+			"quay.io": {"auth": "ZmlsZTpzZWNyZXQ="},
+			"docker.io": {"auth": "ZG9ja2VyOmh1Yg=="}
+		}}`), 0o600)).To(Succeed())
+		// Ignore credentials in the default locations
+		GinkgoT().Setenv("REGISTRY_AUTH_FILE", filepath.Join(GinkgoT().TempDir(), "nonexistent.json"))
+	})
+
+	DescribeTable("should resolve credentials in the expected order",
+		func(auth *AuthConfig, imgRef string, expected authn.AuthConfig) {
+			auth.AuthFile = authFile
+
+			ref, err := crname.ParseReference(imgRef)
+			Expect(err).ToNot(HaveOccurred())
+
+			authenticator, err := auth.Resolve(ref.Context())
+			Expect(err).ToNot(HaveOccurred())
+			authConfig, err := authenticator.Authorization()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*authConfig).To(Equal(expected))
+
+			creds, err := auth.systemContextCredentials(imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			if expected == (authn.AuthConfig{}) {
+				Expect(creds).To(BeNil())
+			} else {
+				Expect(creds.Username).To(Equal(expected.Username))
+				Expect(creds.Password).To(Equal(expected.Password))
+			}
+		},
+		Entry("auth file", &AuthConfig{}, "quay.io/containerdisks/fedora:43",
+			authn.AuthConfig{Username: "file", Password: "secret"}),
+		Entry("auth file with docker hub", &AuthConfig{}, "fedora:43",
+			authn.AuthConfig{Username: "docker", Password: "hub"}),
+		Entry("default credentials before auth file",
+			&AuthConfig{Credentials: &Credentials{Username: "default", Password: "pw"}},
+			"quay.io/containerdisks/fedora:43",
+			authn.AuthConfig{Username: "default", Password: "pw"}),
+		Entry("most specific registry credentials first",
+			&AuthConfig{
+				Credentials: &Credentials{Username: "default", Password: "pw"},
+				Registries: map[string]Credentials{
+					"quay.io":                {Username: "registry", Password: "pw"},
+					"quay.io/containerdisks": {Username: "org", Password: "pw"},
+				},
+			},
+			"quay.io/containerdisks/fedora:43",
+			authn.AuthConfig{Username: "org", Password: "pw"}),
+		Entry("anonymous without matching credentials", &AuthConfig{}, "registry.example.com/containerdisks/fedora:43",
+			authn.AuthConfig{}),
+	)
+
+})
+
+func TestRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Repository Suite")
+}
@@ -12,10 +12,13 @@ import (
 	crname "github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/pkg/errors"
 	"go.podman.io/image/v5/image"
 	"go.podman.io/image/v5/transports/alltransports"
 	"go.podman.io/image/v5/types"
+
+	"kubevirt.io/containerdisks/pkg/network"
 )
 
 type ImageInfo struct {
@@ -31,18 +34,35 @@ type ImageInfo struct {
 
 type Repository interface {
 	ImageMetadata(imgRef, arch string, insecure bool) (*ImageInfo, error)
-	PushImage(ctx context.Context, img v1.Image, imgRef string) error
-	PushImageIndex(ctx context.Context, img v1.ImageIndex, imgRef string) error
+	PushImage(ctx context.Context, img v1.Image, imgRef string, insecure bool) error
+	PushImageIndex(ctx context.Context, img v1.ImageIndex, imgRef string, insecure bool) error
 	CopyImage(ctx context.Context, srcRef, dstRef string, insecure bool) error
+	Image(ctx context.Context, imgRef string, insecure bool) (v1.Image, error)
+	ImageIndex(ctx context.Context, imgRef string, insecure bool) (v1.ImageIndex, error)
+	ListTags(ctx context.Context, repo string, insecure bool) ([]string, error)
+	ImageDigest(ctx context.Context, imgRef string, insecure bool) (string, error)
+	DeleteManifest(ctx context.Context, imgRef string, insecure bool) error
 }
 
-type RepositoryImpl struct{}
+type RepositoryImpl struct {
+	// Auth configures the registry credentials. If empty, the default credential locations are used.
+	Auth AuthConfig
+	// Network configures TLS and proxies. If nil, the defaults of the clients are used.
+	Network *network.Config
+}
 
 func (r RepositoryImpl) ImageMetadata(imgRef, arch string, insecure bool) (imageInfo *ImageInfo, retErr error) {
+	creds, err := r.Auth.systemContextCredentials(imgRef)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting credentials")
+	}
 	sys := &types.SystemContext{
 		OCIInsecureSkipTLSVerify: insecure,
 		ArchitectureChoice:       arch,
 		OSChoice:                 "linux",
+		DockerAuthConfig:         creds,
+		DockerCertPath:           r.Network.CertDir(),
+		DockerProxy:              r.Network.Proxy,
 	}
 	if insecure {
 		sys.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
@@ -82,29 +102,67 @@ func (r RepositoryImpl) ImageMetadata(imgRef, arch string, insecure bool) (image
 	return imageInfo, retErr
 }
 
-func (r RepositoryImpl) PushImage(ctx context.Context, img v1.Image, imgRef string) error {
-	return crane.Push(img, imgRef, crane.WithContext(ctx))
+func (r RepositoryImpl) PushImage(ctx context.Context, img v1.Image, imgRef string, insecure bool) error {
+	return crane.Push(img, imgRef, r.craneOptions(ctx, insecure)...)
 }
 
-func (r RepositoryImpl) PushImageIndex(ctx context.Context, imageIndex v1.ImageIndex, imageRef string) error {
-	ref, err := crname.ParseReference(imageRef)
+func (r RepositoryImpl) PushImageIndex(ctx context.Context, imageIndex v1.ImageIndex, imageRef string, insecure bool) error {
+	options := crane.GetOptions(r.craneOptions(ctx, insecure)...)
+	ref, err := crname.ParseReference(imageRef, options.Name...)
 	if err != nil {
 		return err
 	}
 
-	return remote.WriteIndex(ref, imageIndex, crane.GetOptions(crane.WithContext(ctx)).Remote...)
+	return remote.WriteIndex(ref, imageIndex, options.Remote...)
 }
 
 func (r RepositoryImpl) CopyImage(ctx context.Context, srcRef, dstRef string, insecure bool) error {
+	return crane.Copy(srcRef, dstRef, r.craneOptions(ctx, insecure)...)
+}
+
+func (r RepositoryImpl) Image(ctx context.Context, imgRef string, insecure bool) (v1.Image, error) {
+	return crane.Pull(imgRef, r.craneOptions(ctx, insecure)...)
+}
+
+func (r RepositoryImpl) ImageIndex(ctx context.Context, imgRef string, insecure bool) (v1.ImageIndex, error) {
+	options := crane.GetOptions(r.craneOptions(ctx, insecure)...)
+	ref, err := crname.ParseReference(imgRef, options.Name...)
+	if err != nil {
+		return nil, err
+	}
+
+	return remote.Index(ref, options.Remote...)
+}
+
+func (r RepositoryImpl) ListTags(ctx context.Context, repo string, insecure bool) ([]string, error) {
+	return crane.ListTags(repo, r.craneOptions(ctx, insecure)...)
+}
+
+func (r RepositoryImpl) ImageDigest(ctx context.Context, imgRef string, insecure bool) (string, error) {
+	return crane.Digest(imgRef, r.craneOptions(ctx, insecure)...)
+}
+
+// DeleteManifest deletes the manifest referenced by imgRef together with all tags pointing to it. imgRef should
+// reference a digest, registries like docker distribution reject deleting a tag.
+func (r RepositoryImpl) DeleteManifest(ctx context.Context, imgRef string, insecure bool) error {
+	return crane.Delete(imgRef, r.craneOptions(ctx, insecure)...)
+}
+
+func (r RepositoryImpl) craneOptions(ctx context.Context, insecure bool) []crane.Option {
 	options := []crane.Option{
 		crane.WithContext(ctx),
+		crane.WithAuthFromKeychain(&r.Auth),
 	}
 
 	if insecure {
 		options = append(options, crane.Insecure)
 	}
+	// A custom transport disables the TLS relaxation of crane.Insecure, so the transport has to skip verification itself.
+	if r.Network != nil {
+		options = append(options, crane.WithTransport(r.Network.Transport(insecure)))
+	}
 
-	return crane.Copy(srcRef, dstRef, options...)
+	return options
 }
 
 func parseImageSource(ctx context.Context, sys *types.SystemContext, name string) (types.ImageSource, error) {
@@ -116,7 +174,21 @@ func parseImageSource(ctx context.Context, sys *types.SystemContext, name string
 	return ref.NewImageSource(ctx, sys)
 }
 
+// DigestReference returns a reference to the image with the given digest in the repository of imgRef.
+func DigestReference(imgRef, digest string) (string, error) {
+	ref, err := crname.ParseReference(imgRef)
+	if err != nil {
+		return "", err
+	}
+
+	return ref.Context().Digest(digest).String(), nil
+}
+
 func IsManifestUnknownError(err error) bool {
+	if hasTransportErrorCode(err, transport.ManifestUnknownErrorCode) {
+		return true
+	}
+
 	ec := getErrorCode(err)
 	if ec == nil {
 		return false
@@ -131,6 +203,10 @@ func IsManifestUnknownError(err error) bool {
 }
 
 func IsRepositoryUnknownError(err error) bool {
+	if hasTransportErrorCode(err, transport.NameUnknownErrorCode) {
+		return true
+	}
+
 	ec := getErrorCode(err)
 	if ec == nil {
 		return false
@@ -163,6 +239,22 @@ func IsArchUnknownError(err error) bool {
 	return strings.Contains(err.Error(), "no image found in manifest list for architecture")
 }
 
+// hasTransportErrorCode checks errors returned by crane, which do not use the errcode types of docker distribution.
+func hasTransportErrorCode(err error, code transport.ErrorCode) bool {
+	var transportErr *transport.Error
+	if !errors.As(err, &transportErr) {
+		return false
+	}
+
+	for _, diagnostic := range transportErr.Errors {
+		if diagnostic.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
 func getErrorCode(err error) errcode.ErrorCoder {
 	for {
 		if unwrapped := errors.Unwrap(err); unwrapped != nil {
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	v1 "kubevirt.io/api/core/v1"
+	kvirtcli "kubevirt.io/client-go/kubecli"
+	kvcorev1 "kubevirt.io/client-go/kubevirt/typed/core/v1"
+
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+const (
+	// consoleTimeout bounds waiting for the guest on the serial console, like retryTest bounds other tests.
+	consoleTimeout = maxRetries * retryDuration
+	// consoleConnectTimeout bounds connecting to the serial console.
+	consoleConnectTimeout = 30 * time.Second
+	// maxConsoleOutput is the amount of console output kept for matching and error messages.
+	maxConsoleOutput = 4096
+)
+
+var (
+	loginPrompt       = regexp.MustCompile(`login: *$`)
+	cloudInitFinished = regexp.MustCompile(`Cloud-init v\. \S+ finished at`)
+)
+
+// Console waits for a login prompt or for cloud-init to finish on the serial console of the guest.
+// It needs neither networking nor the guest agent and can be used for minimal images.
+func Console(ctx context.Context, vmi *v1.VirtualMachineInstance, _ *api.ArtifactTestParams) error {
+	return withConsole(ctx, vmi, func(c *console) error {
+		return c.waitForBoot()
+	})
+}
+
+// withConsole connects to the serial console of vmi and runs fn, which is aborted once consoleTimeout passed.
+func withConsole(ctx context.Context, vmi *v1.VirtualMachineInstance, fn func(c *console) error) error {
+	client, err := kvirtcli.GetKubevirtClient()
+	if err != nil {
+		return err
+	}
+
+	var stream kvcorev1.StreamInterface
+	err = retryTest(ctx, func() error {
+		stream, err = client.VirtualMachineInstance(vmi.Namespace).SerialConsole(vmi.Name,
+			&kvcorev1.SerialConsoleOptions{ConnectionTimeout: consoleConnectTimeout})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to the serial console: %w", err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	conn := stream.AsConn()
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, consoleTimeout)
+	defer cancel()
+	// Closing the connection unblocks pending reads
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := fn(&console{conn: conn}); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out on the serial console: %w", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// console interacts with a guest through its serial console.
+type console struct {
+	conn io.ReadWriter
+	// output is the console output which was not matched yet.
+	output []byte
+}
+
+// send writes line followed by a newline to the console.
+func (c *console) send(line string) error {
+	_, err := io.WriteString(c.conn, line+"\n")
+	return err
+}
+
+// expect reads from the console until one of patterns matches and returns the index of the matching pattern.
+// Output up to the end of the match is consumed.
+func (c *console) expect(patterns ...*regexp.Regexp) (int, error) {
+	buf := make([]byte, 1024)
+	for {
+		for i, pattern := range patterns {
+			if loc := pattern.FindIndex(c.output); loc != nil {
+				c.output = c.output[loc[1]:]
+				return i, nil
+			}
+		}
+
+		n, err := c.conn.Read(buf)
+		c.output = append(c.output, buf[:n]...)
+		if len(c.output) > maxConsoleOutput {
+			c.output = c.output[len(c.output)-maxConsoleOutput:]
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read the console while waiting for %v: %w, last output: %q", patterns, err, c.output)
+		}
+	}
+}
+
+// waitForBoot waits for a login prompt or for cloud-init to finish.
+func (c *console) waitForBoot() error {
+	// The guest might have booted before connecting, a newline shows the login prompt again
+	if err := c.send(""); err != nil {
+		return err
+	}
+
+	_, err := c.expect(loginPrompt, cloudInitFinished)
+	return err
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"bufio"
+	"io"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeGuest answers every newline on a serial console with a login prompt.
+func fakeGuest(conn net.Conn) {
+	defer conn.Close()
+	lines := bufio.NewScanner(conn)
+	for lines.Scan() {
+		if _, err := io.WriteString(conn, "\r\nguest login: "); err != nil {
+			return
+		}
+	}
+}
+
+var _ = Describe("Console", func() {
+	var guest, conn net.Conn
+
+	BeforeEach(func() {
+		guest, conn = net.Pipe()
+		DeferCleanup(conn.Close)
+	})
+
+	It("waitForBoot should wait for the login prompt", func() {
+		go fakeGuest(guest)
+		c := &console{conn: conn}
+		Expect(c.waitForBoot()).To(Succeed())
+	})
+
+	It("waitForBoot should wait for cloud-init to finish", func() {
+		go func() {
+			defer guest.Close()
+			_, _ = bufio.NewReader(guest).ReadString('\n')
+			_, _ = io.WriteString(guest, "[   12.3] cloud-init[640]: Cloud-init v. 24.4 finished at Mon, 19 Oct 2026 10:00:00 +0000.\r\n")
+		}()
+		c := &console{conn: conn}
+		Expect(c.waitForBoot()).To(Succeed())
+	})
+
+	It("expect should return the last output if the console closes", func() {
+		go func() {
+			_, _ = io.WriteString(guest, "Booting from Hard Disk...")
+			guest.Close()
+		}()
+		c := &console{conn: conn}
+		_, err := c.expect(loginPrompt)
+		Expect(err).To(MatchError(ContainSubstring(`last output: "Booting from Hard Disk..."`)))
+	})
+})
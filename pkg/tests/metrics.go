@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	v1 "kubevirt.io/api/core/v1"
+	kvirtcli "kubevirt.io/client-go/kubecli"
+
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+// sshPollInterval is the interval WaitSSHReachable polls in, it bounds the precision of the measured boot time.
+const sshPollInterval = time.Second
+
+// WaitSSHReachable waits until the user can log in over SSH. It polls more often than SSH, so it can be used to
+// measure how long a guest takes to boot.
+func WaitSSHReachable(ctx context.Context, vmi *v1.VirtualMachineInstance, params *api.ArtifactTestParams) error {
+	kvirtClient, err := kvirtcli.GetKubevirtClient()
+	if err != nil {
+		return err
+	}
+
+	config, err := sshClientConfig(params)
+	if err != nil {
+		return err
+	}
+
+	var sshErr error
+	err = wait.PollUntilContextTimeout(ctx, sshPollInterval, maxRetries*retryDuration, true, func(context.Context) (bool, error) {
+		_, sshErr = runSSH(vmi, kvirtClient, config, "true")
+		return sshErr == nil, nil
+	})
+	if err != nil && sshErr != nil {
+		return fmt.Errorf("%w: %w", err, sshErr)
+	}
+
+	return err
+}
+
+// MemoryUsed returns the memory used by the guest in bytes, which is the total memory minus the memory available
+// for starting new applications.
+func MemoryUsed(vmi *v1.VirtualMachineInstance, params *api.ArtifactTestParams) (int64, error) {
+	kvirtClient, err := kvirtcli.GetKubevirtClient()
+	if err != nil {
+		return 0, err
+	}
+
+	config, err := sshClientConfig(params)
+	if err != nil {
+		return 0, err
+	}
+
+	output, err := runSSH(vmi, kvirtClient, config, "cat /proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+
+	return parseMemInfo(output)
+}
+
+// parseMemInfo returns MemTotal minus MemAvailable of /proc/meminfo in bytes.
+func parseMemInfo(meminfo string) (int64, error) {
+	const kibibyte = 1024
+	values := map[string]int64{}
+	for _, line := range strings.Split(meminfo, "\n") {
+		fields := strings.Fields(line)
+		const kbFields = 3
+		if len(fields) != kbFields || fields[2] != "kB" {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected meminfo line: %q", line)
+		}
+		values[strings.TrimSuffix(fields[0], ":")] = value * kibibyte
+	}
+
+	total, ok := values["MemTotal"]
+	if !ok {
+		return 0, errors.New("MemTotal is missing in meminfo")
+	}
+	available, ok := values["MemAvailable"]
+	if !ok {
+		return 0, errors.New("MemAvailable is missing in meminfo")
+	}
+
+	return total - available, nil
+}
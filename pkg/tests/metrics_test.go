@@ -0,0 +1,24 @@
+package tests
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const meminfo = `MemTotal:        1001716 kB
+MemFree:          507236 kB
+MemAvailable:     752160 kB
+Buffers:            3624 kB
+HugePages_Total:       0
+`
+
+var _ = Describe("Metrics", func() {
+	It("parseMemInfo should return the used memory in bytes", func() {
+		Expect(parseMemInfo(meminfo)).To(Equal(int64((1001716 - 752160) * 1024)))
+	})
+
+	It("parseMemInfo should fail if MemAvailable is missing", func() {
+		_, err := parseMemInfo("MemTotal:        1001716 kB\n")
+		Expect(err).To(MatchError("MemAvailable is missing in meminfo"))
+	})
+})
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "kubevirt.io/api/core/v1"
+	kvirtcli "kubevirt.io/client-go/kubecli"
+
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+// OSIdentity is the identity a guest OS is expected to report. Empty fields are not checked.
+type OSIdentity struct {
+	// ID is the ID field of /etc/os-release, e.g. "fedora".
+	ID string
+	// VersionID is the VERSION_ID field of /etc/os-release. More specific versions match as well, e.g. "9" matches "9.6".
+	VersionID string
+	// Arch is the machine hardware name reported by uname -m, e.g. "x86_64".
+	Arch string
+}
+
+// OSRelease returns a test which reads /etc/os-release and uname -m over SSH and compares them with expected.
+func OSRelease(expected OSIdentity) api.ArtifactTest {
+	return func(ctx context.Context, vmi *v1.VirtualMachineInstance, params *api.ArtifactTestParams) error {
+		kvirtClient, err := kvirtcli.GetKubevirtClient()
+		if err != nil {
+			return err
+		}
+
+		config, err := sshClientConfig(params)
+		if err != nil {
+			return err
+		}
+
+		var osRelease, machine string
+		err = retryTest(ctx, func() error {
+			if osRelease, err = runSSH(vmi, kvirtClient, config, "cat /etc/os-release"); err != nil {
+				return err
+			}
+			machine, err = runSSH(vmi, kvirtClient, config, "uname -m")
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		actual := parseOSRelease(osRelease)
+		actual.Arch = strings.TrimSpace(machine)
+		if diff := expected.diff(&actual); diff != "" {
+			return fmt.Errorf("guest OS identity does not match:\n%s", diff)
+		}
+
+		return nil
+	}
+}
+
+// parseOSRelease returns the identity of an os-release(5) file, the architecture is not part of it.
+func parseOSRelease(content string) OSIdentity {
+	identity := OSIdentity{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || strings.HasPrefix(key, "#") {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, "'")
+		}
+
+		switch key {
+		case "ID":
+			identity.ID = value
+		case "VERSION_ID":
+			identity.VersionID = value
+		}
+	}
+
+	return identity
+}
+
+// diff returns one line per field of actual which does not match, or an empty string if all fields match.
+func (o *OSIdentity) diff(actual *OSIdentity) string {
+	var lines []string
+	if o.ID != "" && o.ID != actual.ID {
+		lines = append(lines, fmt.Sprintf("  ID: expected %q, got %q", o.ID, actual.ID))
+	}
+	if o.VersionID != "" && o.VersionID != actual.VersionID && !strings.HasPrefix(actual.VersionID, o.VersionID+".") {
+		lines = append(lines, fmt.Sprintf("  VERSION_ID: expected %q, got %q", o.VersionID, actual.VersionID))
+	}
+	if o.Arch != "" && o.Arch != actual.Arch {
+		lines = append(lines, fmt.Sprintf("  uname -m: expected %q, got %q", o.Arch, actual.Arch))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const almaLinuxOSRelease = `NAME="AlmaLinux"
+VERSION="9.6 (Sage Margay)"
+ID=almalinux
+ID_LIKE="rhel centos fedora"
+VERSION_ID='9.6'
+# comment
+PRETTY_NAME="AlmaLinux 9.6 (Sage Margay)"
+`
+
+var _ = Describe("OSRelease", func() {
+	It("parseOSRelease should parse quoted and unquoted values", func() {
+		Expect(parseOSRelease(almaLinuxOSRelease)).To(Equal(OSIdentity{ID: "almalinux", VersionID: "9.6"}))
+	})
+
+	DescribeTable("diff should compare the identities",
+		func(expected, actual OSIdentity, diff string) {
+			Expect(expected.diff(&actual)).To(Equal(diff))
+		},
+		Entry("matching identity",
+			OSIdentity{ID: "ubuntu", VersionID: "24.04", Arch: "x86_64"},
+			OSIdentity{ID: "ubuntu", VersionID: "24.04", Arch: "x86_64"},
+			"",
+		),
+		Entry("more specific version",
+			OSIdentity{ID: "almalinux", VersionID: "9"},
+			OSIdentity{ID: "almalinux", VersionID: "9.6", Arch: "aarch64"},
+			"",
+		),
+		Entry("version with the same prefix",
+			OSIdentity{VersionID: "1"},
+			OSIdentity{VersionID: "10"},
+			`  VERSION_ID: expected "1", got "10"`,
+		),
+		Entry("wrong release and architecture",
+			OSIdentity{ID: "ubuntu", VersionID: "24.04", Arch: "x86_64"},
+			OSIdentity{ID: "ubuntu", VersionID: "22.04", Arch: "aarch64"},
+			"  VERSION_ID: expected \"24.04\", got \"22.04\"\n  uname -m: expected \"x86_64\", got \"aarch64\"",
+		),
+	)
+})
+
+func TestTests(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tests Suite")
+}
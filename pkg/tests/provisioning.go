@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	v1 "kubevirt.io/api/core/v1"
+	kvirtcli "kubevirt.io/client-go/kubecli"
+
+	"kubevirt.io/containerdisks/pkg/api"
+	"kubevirt.io/containerdisks/pkg/docs"
+)
+
+// provisioning describes how to check that first-boot provisioning of a guest succeeded.
+type provisioning struct {
+	name string
+	// statusCommand waits for provisioning to finish and prints its status.
+	statusCommand string
+	// checkStatus returns an error if the output or exit status of statusCommand report a failure.
+	checkStatus func(status string, exitStatus int) error
+	// logCommand prints the provisioning log, which is collected on failure.
+	logCommand string
+}
+
+var cloudInit = provisioning{
+	name:          "cloud-init",
+	statusCommand: "cloud-init status --wait",
+	checkStatus: func(status string, exitStatus int) error {
+		// cloud-init exits with 2 if it finished with recoverable errors, e.g. deprecated keys in the user data
+		const recoverableErrors = 2
+		if cloudInitStatus(status) != "done" || (exitStatus != 0 && exitStatus != recoverableErrors) {
+			return fmt.Errorf("unexpected status with exit status %d: %s", exitStatus, strings.TrimSpace(status))
+		}
+		return nil
+	},
+	logCommand: "sudo -n tail -n 200 /var/log/cloud-init.log",
+}
+
+// cloudInitStatus returns the value of the status line in the output of "cloud-init status".
+func cloudInitStatus(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "status:"); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// CloudInit waits for cloud-init to finish and checks that it succeeded and applied the user data.
+// The cloud-init log is collected on failure.
+func CloudInit(ctx context.Context, vmi *v1.VirtualMachineInstance, params *api.ArtifactTestParams) error {
+	return cloudInit.test(ctx, vmi, params)
+}
+
+func (p *provisioning) test(ctx context.Context, vmi *v1.VirtualMachineInstance, params *api.ArtifactTestParams) error {
+	kvirtClient, err := kvirtcli.GetKubevirtClient()
+	if err != nil {
+		return err
+	}
+
+	config, err := sshClientConfig(params)
+	if err != nil {
+		return err
+	}
+
+	var status string
+	var statusErr error
+	err = retryTest(ctx, func() error {
+		status, statusErr = runSSH(vmi, kvirtClient, config, p.statusCommand)
+		// Only connection failures are retried, the command itself fails if provisioning failed
+		if exitErr := (&ssh.ExitError{}); errors.As(statusErr, &exitErr) {
+			return nil
+		}
+		return statusErr
+	})
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	exitStatus := 0
+	if exitErr := (&ssh.ExitError{}); errors.As(statusErr, &exitErr) {
+		exitStatus = exitErr.ExitStatus()
+	}
+	statusErr = p.checkStatus(status, exitStatus)
+	if statusErr == nil {
+		statusErr = p.checkUserData(vmi, kvirtClient, config, &params.UserData)
+	}
+	if statusErr != nil {
+		p.collectLog(vmi, kvirtClient, config)
+		return fmt.Errorf("%s provisioning failed: %w", p.name, statusErr)
+	}
+
+	return nil
+}
+
+func (p *provisioning) checkUserData(vmi *v1.VirtualMachineInstance, kvirtClient kvirtcli.KubevirtClient,
+	config *ssh.ClientConfig, userData *docs.UserData,
+) error {
+	hostname, err := runSSH(vmi, kvirtClient, config, "hostname")
+	if err != nil {
+		return err
+	}
+	authorizedKeys, err := runSSH(vmi, kvirtClient, config, "cat ~/.ssh/authorized_keys")
+	if err != nil {
+		return err
+	}
+
+	return checkUserData(hostname, authorizedKeys, userData)
+}
+
+// checkUserData returns an error if the hostname or authorized keys of the guest do not match userData.
+func checkUserData(hostname, authorizedKeys string, userData *docs.UserData) error {
+	var errs []error
+	hostname = strings.TrimSpace(hostname)
+	if userData.Hostname != "" && hostname != userData.Hostname && !strings.HasPrefix(hostname, userData.Hostname+".") {
+		errs = append(errs, fmt.Errorf("expected hostname %q, got %q", userData.Hostname, hostname))
+	}
+	for _, key := range userData.AuthorizedKeys {
+		if !strings.Contains(authorizedKeys, key) {
+			errs = append(errs, fmt.Errorf("authorized key %q was not applied", key))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *provisioning) collectLog(vmi *v1.VirtualMachineInstance, kvirtClient kvirtcli.KubevirtClient, config *ssh.ClientConfig) {
+	log := logrus.WithField("vmi", vmi.Name)
+	output, err := runSSH(vmi, kvirtClient, config, p.logCommand)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to collect the %s log", p.name)
+	}
+	if output != "" {
+		log.Errorf("%s log:\n%s", p.name, output)
+	}
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+
+	"kubevirt.io/containerdisks/pkg/docs"
+)
+
+var _ = Describe("Provisioning", func() {
+	userData := &docs.UserData{
+		Username:       "fedora",
+		AuthorizedKeys: []string{"ssh-ed25519 AAAAC3 key"},
+		Hostname:       "fedora-abcde",
+	}
+
+	DescribeTable("cloud-init status",
+		func(status string, exitStatus int, matchErr types.GomegaMatcher) {
+			Expect(cloudInit.checkStatus(status, exitStatus)).To(matchErr)
+		},
+		Entry("done", "\nstatus: done\n", 0, Succeed()),
+		Entry("done with recoverable errors", "status: done\n", 2, Succeed()),
+		Entry("error", "status: error\n", 1, MatchError("unexpected status with exit status 1: status: error")),
+		Entry("recoverable errors while running", "status: running\n", 2,
+			MatchError("unexpected status with exit status 2: status: running")),
+		Entry("status which only contains done", "status: not done\n", 0,
+			MatchError("unexpected status with exit status 0: status: not done")),
+	)
+
+	DescribeTable("checkUserData",
+		func(hostname, authorizedKeys string, matchErr types.GomegaMatcher) {
+			Expect(checkUserData(hostname, authorizedKeys, userData)).To(matchErr)
+		},
+		Entry("applied", "fedora-abcde\n", "ssh-rsa other\nssh-ed25519 AAAAC3 key\n", Succeed()),
+		Entry("fully qualified hostname", "fedora-abcde.example.com\n", "ssh-ed25519 AAAAC3 key\n", Succeed()),
+		Entry("wrong hostname and missing key", "localhost\n", "ssh-rsa other\n", MatchError(
+			"expected hostname \"fedora-abcde\", got \"localhost\"\nauthorized key \"ssh-ed25519 AAAAC3 key\" was not applied",
+		)),
+	)
+})
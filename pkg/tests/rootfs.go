@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "kubevirt.io/api/core/v1"
+	kvirtcli "kubevirt.io/client-go/kubecli"
+
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+// minRootFilesystemShare is the share of the disk the root filesystem has to use after growing,
+// the remainder is left for boot partitions and filesystem overhead.
+const minRootFilesystemShare = 0.75
+
+// RootFilesystemGrown returns a test which checks over SSH that the root filesystem grew to use most of a
+// disk with diskSize bytes. The disk has to be much bigger than the disk of the containerdisk.
+func RootFilesystemGrown(diskSize int64) api.ArtifactTest {
+	return func(ctx context.Context, vmi *v1.VirtualMachineInstance, params *api.ArtifactTestParams) error {
+		kvirtClient, err := kvirtcli.GetKubevirtClient()
+		if err != nil {
+			return err
+		}
+
+		config, err := sshClientConfig(params)
+		if err != nil {
+			return err
+		}
+
+		var output string
+		err = retryTest(ctx, func() error {
+			output, err = runSSH(vmi, kvirtClient, config, "df -B1 --output=size /")
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		size, err := parseDFSize(output)
+		if err != nil {
+			return err
+		}
+		if minSize := int64(float64(diskSize) * minRootFilesystemShare); size < minSize {
+			return fmt.Errorf("root filesystem did not grow: size is %d bytes, expected at least %d bytes on a disk of %d bytes",
+				size, minSize, diskSize)
+		}
+
+		return nil
+	}
+}
+
+// parseDFSize parses the output of df --output=size, which is a header followed by the size.
+func parseDFSize(output string) (int64, error) {
+	lines := strings.Fields(output)
+	const dfLines = 2
+	if len(lines) != dfLines {
+		return 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+
+	size, err := strconv.ParseInt(lines[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+
+	return size, nil
+}
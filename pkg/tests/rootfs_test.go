@@ -0,0 +1,17 @@
+package tests
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RootFilesystemGrown", func() {
+	It("parseDFSize should parse the size of the filesystem", func() {
+		Expect(parseDFSize("  1B-blocks\n21003583488\n")).To(BeEquivalentTo(21003583488))
+	})
+
+	It("parseDFSize should fail for unexpected output", func() {
+		_, err := parseDFSize("df: /: No such file or directory\n")
+		Expect(err).To(MatchError(ContainSubstring("unexpected df output")))
+	})
+})
@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	v1 "kubevirt.io/api/core/v1"
+	kvirtcli "kubevirt.io/client-go/kubecli"
+	"sigs.k8s.io/yaml"
+
+	"kubevirt.io/containerdisks/pkg/api"
+)
+
+// Spec is a declarative guest test, which runs a shell command over SSH and checks its exit code and output.
+type Spec struct {
+	// Name describes the test, e.g. "chronyd is running".
+	Name string `json:"name"`
+	// Command is run by the shell of the user in the guest.
+	Command string `json:"command"`
+	// ExitCode is the expected exit code of the command.
+	ExitCode int `json:"exitCode,omitempty"`
+	// Output is a regular expression the standard output of the command has to match, if not empty.
+	Output string `json:"output,omitempty"`
+	// Artifacts are path.Match patterns of the artifacts the test runs for, e.g. "fedora:*".
+	// The test runs for all artifacts if empty.
+	Artifacts []string `json:"artifacts,omitempty"`
+	// Retry retries the command until the exit code and output match, e.g. for services which start late.
+	// Otherwise only connection failures are retried.
+	Retry bool `json:"retry,omitempty"`
+
+	// output is the compiled Output, it is set by validating the test.
+	output *regexp.Regexp
+}
+
+// SpecFile is the content of a file with declarative guest tests.
+type SpecFile struct {
+	Tests []Spec `json:"tests"`
+}
+
+// sshSpec checks that the user can log in over SSH.
+var sshSpec = mustSpec(Spec{
+	Name:    "SSH",
+	Command: "echo hello",
+	Output:  "^hello\n$",
+	Retry:   true,
+})
+
+func mustSpec(s Spec) *Spec {
+	if err := s.validate(); err != nil {
+		panic(err)
+	}
+	return &s
+}
+
+// LoadSpecs reads and validates the declarative guest tests of a YAML file.
+func LoadSpecs(fileName string) ([]Spec, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	specFile := SpecFile{}
+	if err := yaml.UnmarshalStrict(data, &specFile); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", fileName, err)
+	}
+
+	for i := range specFile.Tests {
+		if err := specFile.Tests[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid test %d in %s: %v", i, fileName, err)
+		}
+	}
+
+	return specFile.Tests, nil
+}
+
+func (s *Spec) validate() error {
+	if s.Name == "" {
+		return errors.New("name is missing")
+	}
+	if s.Command == "" {
+		return fmt.Errorf("%s: command is missing", s.Name)
+	}
+	if s.Output != "" {
+		output, err := regexp.Compile(s.Output)
+		if err != nil {
+			return fmt.Errorf("%s: invalid output: %v", s.Name, err)
+		}
+		s.output = output
+	}
+	for _, pattern := range s.Artifacts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s: invalid artifact pattern %q: %v", s.Name, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Selects returns whether the test runs for the artifact with the given description, e.g. "fedora:43".
+func (s *Spec) Selects(description string) bool {
+	if len(s.Artifacts) == 0 {
+		return true
+	}
+	for _, pattern := range s.Artifacts {
+		if matched, _ := path.Match(pattern, description); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Test returns the test as api.ArtifactTest. The test has to be validated, e.g. by loading it with LoadSpecs.
+func (s *Spec) Test() api.ArtifactTest {
+	return s.run
+}
+
+func (s *Spec) run(ctx context.Context, vmi *v1.VirtualMachineInstance, params *api.ArtifactTestParams) error {
+	kvirtClient, err := kvirtcli.GetKubevirtClient()
+	if err != nil {
+		return err
+	}
+
+	config, err := sshClientConfig(params)
+	if err != nil {
+		return err
+	}
+
+	var checkErr error
+	err = retryTest(ctx, func() error {
+		output, runErr := runSSH(vmi, kvirtClient, config, s.Command)
+		exitCode := 0
+		if exitErr := (&ssh.ExitError{}); errors.As(runErr, &exitErr) {
+			exitCode = exitErr.ExitStatus()
+		} else if runErr != nil {
+			return runErr
+		}
+
+		checkErr = s.check(exitCode, output)
+		if s.Retry {
+			return checkErr
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.Name, err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if checkErr != nil {
+		return fmt.Errorf("%s: %w", s.Name, checkErr)
+	}
+
+	return nil
+}
+
+// check returns an error if the exit code or the output of the command are not as expected.
+func (s *Spec) check(exitCode int, output string) error {
+	if exitCode != s.ExitCode {
+		return fmt.Errorf("%q exited with %d, expected %d, output: %q", s.Command, exitCode, s.ExitCode, strings.TrimSpace(output))
+	}
+	if s.output != nil && !s.output.MatchString(output) {
+		return fmt.Errorf("output of %q does not match %q: %q", s.Command, s.Output, output)
+	}
+
+	return nil
+}
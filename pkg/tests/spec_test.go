@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+var _ = Describe("Spec", func() {
+	writeSpecs := func(content string) string {
+		fileName := filepath.Join(GinkgoT().TempDir(), "specs.yaml")
+		Expect(os.WriteFile(fileName, []byte(content), 0o600)).To(Succeed())
+		return fileName
+	}
+
+	It("LoadSpecs should load the tests", func() {
+		specs, err := LoadSpecs("testdata/specs.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(specs).To(HaveLen(3))
+		Expect(specs[0].Name).To(Equal("chronyd is running"))
+		Expect(specs[0].Retry).To(BeTrue())
+		Expect(specs[2].ExitCode).To(Equal(1))
+	})
+
+	DescribeTable("LoadSpecs should reject invalid tests",
+		func(content string, matchErr types.GomegaMatcher) {
+			_, err := LoadSpecs(writeSpecs(content))
+			Expect(err).To(matchErr)
+		},
+		Entry("missing name", "tests:\n- command: 'true'\n", MatchError(ContainSubstring("invalid test 0 in"))),
+		Entry("missing command", "tests:\n- name: check\n", MatchError(ContainSubstring("check: command is missing"))),
+		Entry("invalid output", "tests:\n- name: check\n  command: 'true'\n  output: '('\n",
+			MatchError(ContainSubstring("check: invalid output"))),
+		Entry("invalid artifact pattern", "tests:\n- name: check\n  command: 'true'\n  artifacts: ['fedora:[']\n",
+			MatchError(ContainSubstring(`check: invalid artifact pattern "fedora:["`))),
+		Entry("unknown field", "tests:\n- name: check\n  command: 'true'\n  stdout: ok\n",
+			MatchError(ContainSubstring(`unknown field "stdout"`))),
+	)
+
+	DescribeTable("Selects should match the artifact patterns",
+		func(artifacts []string, description string, selected bool) {
+			spec := &Spec{Name: "check", Command: "true", Artifacts: artifacts}
+			Expect(spec.Selects(description)).To(Equal(selected))
+		},
+		Entry("all artifacts", nil, "ubuntu:24.04", true),
+		Entry("matching name", []string{"centos-stream:*", "fedora:*"}, "fedora:43", true),
+		Entry("other name", []string{"fedora:*"}, "fedora-coreos:stable", false),
+		Entry("exact version", []string{"ubuntu:24.04"}, "ubuntu:25.04", false),
+	)
+
+	DescribeTable("check should compare the exit code and output",
+		func(exitCode int, output string, matchErr types.GomegaMatcher) {
+			spec := mustSpec(Spec{Name: "chronyd", Command: "systemctl is-active chronyd", Output: "^active"})
+			Expect(spec.check(exitCode, output)).To(matchErr)
+		},
+		Entry("expected", 0, "active\n", Succeed()),
+		Entry("unexpected exit code", 3, "inactive\n",
+			MatchError(`"systemctl is-active chronyd" exited with 3, expected 0, output: "inactive"`)),
+		Entry("mismatching output", 0, "unknown\n",
+			MatchError(`output of "systemctl is-active chronyd" does not match "^active": "unknown\n"`)),
+	)
+
+	It("the SSH test should expect the echoed output", func() {
+		Expect(sshSpec.check(0, "hello\n")).To(Succeed())
+		Expect(sshSpec.check(0, "")).To(HaveOccurred())
+	})
+})
@@ -11,54 +11,55 @@ import (
 	"kubevirt.io/containerdisks/pkg/api"
 )
 
+// SSH checks that the user can log in over SSH, it is expressed as declarative test.
 func SSH(ctx context.Context, vmi *v1.VirtualMachineInstance, params *api.ArtifactTestParams) error {
-	kvirtClient, err := kvirtcli.GetKubevirtClient()
-	if err != nil {
-		return err
-	}
+	return sshSpec.run(ctx, vmi, params)
+}
 
+func sshClientConfig(params *api.ArtifactTestParams) (*ssh.ClientConfig, error) {
 	signer, err := ssh.NewSignerFromKey(params.PrivateKey)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Test SSH while deliberately ignoring insecure host keys
-	config := &ssh.ClientConfig{
+	return &ssh.ClientConfig{
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec
 		User:            params.Username,
 		Auth: []ssh.AuthMethod{
 			ssh.PublicKeys(signer),
 		},
-	}
-
-	return retryTest(ctx, func() error {
-		return testSSH(vmi, kvirtClient, config)
-	})
+	}, nil
 }
 
-func testSSH(vmi *v1.VirtualMachineInstance, kvirtClient kvirtcli.KubevirtClient, config *ssh.ClientConfig) error {
+// runSSH runs command in the guest and returns its standard output, which is also returned if the command fails.
+func runSSH(vmi *v1.VirtualMachineInstance, kvirtClient kvirtcli.KubevirtClient, config *ssh.ClientConfig, command string) (string, error) {
 	const sshPort = 22
 	tunnel, err := kvirtClient.VirtualMachineInstance(vmi.Namespace).PortForward(vmi.Name, sshPort, "tcp")
 	if err != nil {
-		return fmt.Errorf("failed to forward ssh port: %w", err)
+		return "", fmt.Errorf("failed to forward ssh port: %w", err)
 	}
 
 	conn := tunnel.AsConn()
 	addr := fmt.Sprintf("vmi/%s.%s:22", vmi.Name, vmi.Namespace)
 	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	session, err := ssh.NewClient(sshConn, chans, reqs).NewSession()
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	session, err := client.NewSession()
 	if err != nil {
-		return err
+		return "", err
 	}
+	defer session.Close()
 
-	err = session.Run("echo hello")
+	output, err := session.Output(command)
 	if err != nil {
-		return err
+		return string(output), fmt.Errorf("failed to run %q: %w", command, err)
 	}
 
-	return nil
+	return string(output), nil
 }
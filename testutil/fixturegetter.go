@@ -0,0 +1,146 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"hash"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"kubevirt.io/containerdisks/pkg/http"
+)
+
+// UpdateFixturesEnv re-records all fixtures from the network if set to true, e.g. UPDATE_FIXTURES=true go test ./...
+const UpdateFixturesEnv = "UPDATE_FIXTURES"
+
+// fixtureGetter replays responses from a directory laid out like an upstream mirror,
+// "https://host/path" is read from "<dir>/host/path".
+type fixtureGetter struct {
+	mirror   http.MirrorGetter
+	upstream http.Getter
+	record   bool
+
+	mu       sync.Mutex
+	recorded map[string]bool
+}
+
+func (f *fixtureGetter) GetAll(fileURL string) ([]byte, error) {
+	return f.GetAllWithContext(context.Background(), fileURL)
+}
+
+func (f *fixtureGetter) GetAllWithContext(ctx context.Context, fileURL string) ([]byte, error) {
+	if err := f.recordOnce(fileURL, func() error {
+		content, err := f.upstream.GetAllWithContext(ctx, fileURL)
+		if err != nil {
+			return err
+		}
+		return f.mirror.Store(fileURL, bytes.NewReader(content), nil)
+	}); err != nil {
+		return nil, err
+	}
+
+	return f.mirror.GetAllWithContext(ctx, fileURL)
+}
+
+func (f *fixtureGetter) GetWithChecksum(fileURL string, checksumHasher func() hash.Hash) (http.ReadCloserWithChecksum, error) {
+	return f.GetWithChecksumAndContext(context.Background(), fileURL, checksumHasher)
+}
+
+func (f *fixtureGetter) GetWithChecksumAndContext(ctx context.Context, fileURL string, checksumHasher func() hash.Hash) (
+	http.ReadCloserWithChecksum, error,
+) {
+	if err := f.recordOnce(fileURL, func() error {
+		body, err := f.upstream.GetWithChecksumAndContext(ctx, fileURL, checksumHasher)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		return f.mirror.Store(fileURL, body, nil)
+	}); err != nil {
+		return nil, err
+	}
+
+	return f.mirror.GetWithChecksumAndContext(ctx, fileURL, checksumHasher)
+}
+
+// recordOnce calls record for every URL once if recording is enabled.
+func (f *fixtureGetter) recordOnce(fileURL string, record func() error) error {
+	if !f.record {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.recorded[fileURL] {
+		return nil
+	}
+	if err := record(); err != nil {
+		return err
+	}
+	f.recorded[fileURL] = true
+
+	return nil
+}
+
+// NewFixtureGetter returns a Getter which replays the responses stored in dir. Fixtures are laid out like an
+// upstream mirror, "https://host/path" is read from "<dir>/host/path". If UPDATE_FIXTURES is true, responses
+// are downloaded from the network and stored in dir before they are replayed.
+func NewFixtureGetter(dir string) *fixtureGetter {
+	return &fixtureGetter{
+		mirror:   http.MirrorGetter{Dir: dir},
+		upstream: &http.HTTPGetter{},
+		record:   updateFixtures(),
+		recorded: map[string]bool{},
+	}
+}
+
+// NewFixtureServer serves the fixtures of host in dir, e.g. "<dir>/host/path" is served at "<server.URL>/path".
+// Range requests are supported, which allows testing resumable and parallel downloads.
+func NewFixtureServer(dir, host string) *httptest.Server {
+	return httptest.NewServer(stdhttp.FileServer(stdhttp.Dir(filepath.Join(dir, host))))
+}
+
+// NewUpstreamHandler serves the fixtures in dir for all upstream hosts, "https://host/path" is served from
+// "<dir>/host/path" based on the Host header of the request.
+func NewUpstreamHandler(dir string) stdhttp.Handler {
+	return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		stdhttp.FileServer(stdhttp.Dir(filepath.Join(dir, r.Host))).ServeHTTP(w, r)
+	})
+}
+
+// NewUpstreamClient returns a client which sends all requests to server while keeping the requested host, which
+// allows serving upstream URLs with NewUpstreamHandler.
+func NewUpstreamClient(server *httptest.Server) *stdhttp.Client {
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		panic(err)
+	}
+
+	return &stdhttp.Client{
+		Transport: &upstreamTransport{host: serverURL.Host, transport: server.Client().Transport},
+	}
+}
+
+// upstreamTransport sends all requests to host over plain HTTP.
+type upstreamTransport struct {
+	host      string
+	transport stdhttp.RoundTripper
+}
+
+func (t *upstreamTransport) RoundTrip(req *stdhttp.Request) (*stdhttp.Response, error) {
+	req = req.Clone(req.Context())
+	req.Host = req.URL.Host
+	req.URL.Scheme = "http"
+	req.URL.Host = t.host
+	return t.transport.RoundTrip(req)
+}
+
+func updateFixtures() bool {
+	update, _ := strconv.ParseBool(os.Getenv(UpdateFixturesEnv))
+	return update
+}